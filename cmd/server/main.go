@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,14 +14,24 @@ import (
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
+	"github.com/yourusername/auth-service/internal/audit"
+	"github.com/yourusername/auth-service/internal/auth"
 	"github.com/yourusername/auth-service/internal/config"
 	"github.com/yourusername/auth-service/internal/handler"
 	"github.com/yourusername/auth-service/internal/importer"
+	"github.com/yourusername/auth-service/internal/mailer"
 	"github.com/yourusername/auth-service/internal/middleware"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/oidc"
+	"github.com/yourusername/auth-service/internal/ratelimit"
 	"github.com/yourusername/auth-service/internal/repository"
+	"github.com/yourusername/auth-service/internal/scheduler"
 )
 
 func main() {
+	promoteAdmin := flag.String("promote-admin", "", "promote the user with this email to admin and exit")
+	flag.Parse()
+
 	// Load configuration
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -32,9 +43,6 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Start USDA food import in background
-	go runFoodImport(cfg)
-
 	// Connect to database
 	db, err := connectToDatabase(cfg)
 	if err != nil {
@@ -42,13 +50,70 @@ func main() {
 	}
 	defer db.Close()
 
+	userRepo := repository.NewUserRepository(db)
+
+	// Promote an admin from the command line and exit
+	if *promoteAdmin != "" {
+		if err := promoteUserToAdmin(userRepo, *promoteAdmin); err != nil {
+			log.Fatalf("Failed to promote admin: %v", err)
+		}
+		return
+	}
+
+	// Create the first admin from configuration
+	bootstrapAdmin(userRepo, cfg)
+
+	// Start USDA food import in background
+	go runFoodImport(cfg)
+
 	// Initialize repositories
 	foodRepo := repository.NewFoodRepository(db)
 	diaryRepo := repository.NewDiaryRepository(db)
+	goalRepo := repository.NewGoalRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+
+	// Initialize audit logger
+	auditLog := audit.NewLogger(auditRepo)
+
+	// Initialize token manager
+	tokenManager, err := auth.NewTokenManager(cfg.JWT)
+	if err != nil {
+		log.Fatalf("Failed to configure token signing: %v", err)
+	}
+
+	// Initialize mailer
+	mail, err := mailer.New(cfg.Email)
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
 
 	// Initialize handlers
 	foodHandler := handler.NewFoodHandler(foodRepo)
-	diaryHandler := handler.NewDiaryHandler(diaryRepo, foodRepo)
+	diaryHandler := handler.NewDiaryHandler(diaryRepo, foodRepo, goalRepo, auditLog)
+	goalHandler := handler.NewGoalHandler(goalRepo, auditLog)
+	authHandler := handler.NewAuthHandler(userRepo, tokenRepo, mfaRepo, tokenManager, mail, auditLog, cfg.Security, cfg.Lockout, cfg.Email)
+	userHandler := handler.NewUserHandler(userRepo)
+	auditHandler := handler.NewAuditHandler(auditRepo)
+	adminHandler := handler.NewAdminHandler(userRepo, auditLog)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyRepo, auditLog)
+	mfaHandler := handler.NewMFAHandler(userRepo, mfaRepo, auditLog, cfg.Security, cfg.Lockout)
+	oidcHandler := handler.NewOIDCHandler(authHandler, userRepo, identityRepo, oidc.NewRegistry(cfg.OIDC), auditLog, cfg.OIDC)
+
+	// Start background maintenance jobs
+	jobs := scheduler.New(scheduler.NewPostgresCoordinator(db), cfg.Scheduler.Jitter)
+	if err := registerMaintenanceJobs(jobs, cfg, userRepo, tokenRepo, identityRepo); err != nil {
+		log.Fatalf("Failed to register maintenance jobs: %v", err)
+	}
+	if cfg.Scheduler.Enabled {
+		jobs.Start(context.Background())
+	} else {
+		log.Println("Maintenance scheduler is disabled in configuration")
+	}
+	defer jobs.Stop()
 
 	// Set Gin mode
 	if gin.Mode() == "" {
@@ -58,6 +123,11 @@ func main() {
 	// Create router
 	router := gin.New()
 
+	// Only trust X-Forwarded-For from configured proxies; ClientIP keys rate limits
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
 	// Middleware
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
@@ -70,56 +140,75 @@ func main() {
 		})
 	})
 
+	// JSON Web Key Set for services verifying access tokens
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
+	// Authentication middleware
+	authMiddleware := middleware.AuthMiddleware(tokenManager, userRepo, tokenRepo, apiKeyRepo)
+	requireAccessToken := middleware.RequireAccessToken()
+	requireVerifiedEmail := middleware.RequireVerifiedEmail(cfg.Security.RequireVerifiedEmailForDiary)
+	requireAdmin := middleware.RequireRole(model.RoleAdmin)
+
+	// Rate limiting middleware
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit, ratelimit.NewMemoryStore())
+	loginRateLimit := rateLimiter.Limit(middleware.RateLimitScopeLogin, middleware.KeyByIP)
+	passwordResetRateLimit := rateLimiter.Limit(middleware.RateLimitScopePasswordReset, middleware.KeyByIP)
+
 	// API v1 routes
 	apiV1 := router.Group("/api/v1")
 	{
 		// Auth routes
-		auth := apiV1.Group("/auth")
+		authRoutes := apiV1.Group("/auth")
+		authRoutes.Use(rateLimiter.Limit(middleware.RateLimitScopeAuth, middleware.KeyByIP))
 		{
-			auth.POST("/register", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Register endpoint - to be implemented",
-				})
-			})
-			auth.POST("/login", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Login endpoint - to be implemented",
-				})
-			})
-			auth.POST("/logout", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Logout endpoint - to be implemented",
-				})
-			})
-			auth.POST("/refresh", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Refresh token endpoint - to be implemented",
-				})
-			})
-			auth.POST("/password-reset-request", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Password reset request endpoint - to be implemented",
-				})
-			})
-			auth.POST("/password-reset-confirm", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Password reset confirm endpoint - to be implemented",
-				})
-			})
+			authRoutes.POST("/register", authHandler.Register)
+			authRoutes.POST("/login", loginRateLimit, authHandler.Login)
+			authRoutes.POST("/login/mfa", loginRateLimit, authHandler.LoginMFA)
+			authRoutes.POST("/logout", authMiddleware, requireAccessToken, authHandler.Logout)
+			authRoutes.POST("/logout-all", authMiddleware, requireAccessToken, authHandler.LogoutAll)
+			authRoutes.POST("/verify-email", authHandler.VerifyEmail)
+			authRoutes.POST("/verify-email/resend", authMiddleware, requireAccessToken, authHandler.ResendVerificationEmail)
+			authRoutes.POST("/refresh", authHandler.Refresh)
+			authRoutes.POST("/password-reset-request", passwordResetRateLimit, authHandler.RequestPasswordReset)
+			authRoutes.POST("/password-reset-confirm", passwordResetRateLimit, authHandler.ConfirmPasswordReset)
+			authRoutes.GET("/oidc/:provider/login", oidcHandler.Login)
+			authRoutes.GET("/oidc/:provider/callback", oidcHandler.Callback)
 		}
 
 		// Protected routes (require authentication)
 		protected := apiV1.Group("/protected")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(authMiddleware, rateLimiter.Limit(middleware.RateLimitScopeAPI, middleware.KeyByUser))
 		{
-			protected.GET("/me", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Get current user endpoint - to be implemented",
-				})
-			})
+			// Account routes (access token only, not reachable with API keys)
+			account := protected.Group("", requireAccessToken)
+			{
+				account.GET("/me", userHandler.GetMe)
+				account.PATCH("/me", userHandler.UpdateMe)
+				account.DELETE("/me", userHandler.DeleteMe)
+
+				// Session routes (protected)
+				account.GET("/sessions", authHandler.ListSessions)
+				account.DELETE("/sessions/:id", authHandler.RevokeSession)
+
+				// API key routes (protected)
+				account.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+				account.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+				account.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+				// Two-factor authentication routes (protected)
+				account.POST("/mfa/totp/enroll", mfaHandler.EnrollTOTP)
+				account.POST("/mfa/totp/confirm", mfaHandler.ConfirmTOTP)
+				account.POST("/mfa/totp/disable", mfaHandler.DisableTOTP)
+				account.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+				// Linked identity provider routes (protected)
+				account.GET("/identities", oidcHandler.ListIdentities)
+				account.POST("/identities/:provider", oidcHandler.StartLink)
+				account.DELETE("/identities/:id", oidcHandler.Unlink)
+			}
 
 			// Food routes (protected)
-			foods := protected.Group("/foods")
+			foods := protected.Group("/foods", middleware.RequireScope(model.ScopeFoodsRead))
 			{
 				foods.GET("/search", foodHandler.SearchFoods)
 				foods.GET("/:id", foodHandler.GetFoodByID)
@@ -127,15 +216,29 @@ func main() {
 
 			// Diary routes (protected)
 			diary := protected.Group("/diary")
+			diaryRead := middleware.RequireScope(model.ScopeDiaryRead)
+			diaryWrite := middleware.RequireScope(model.ScopeDiaryWrite)
 			{
-				diary.GET("/entries", diaryHandler.GetDiaryEntries)
-				diary.POST("/entries", diaryHandler.CreateFoodEntry)
-				diary.PUT("/entries/:id", diaryHandler.UpdateFoodEntry)
-				diary.DELETE("/entries/:id", diaryHandler.DeleteFoodEntry)
-				diary.GET("/summary", diaryHandler.GetDiarySummary)
-				diary.POST("/copy", diaryHandler.CopyDiaryEntries)
+				diary.GET("/entries", diaryRead, diaryHandler.GetDiaryEntries)
+				diary.POST("/entries", diaryWrite, requireVerifiedEmail, diaryHandler.CreateFoodEntry)
+				diary.PUT("/entries/:id", diaryWrite, requireVerifiedEmail, diaryHandler.UpdateFoodEntry)
+				diary.DELETE("/entries/:id", diaryWrite, requireVerifiedEmail, diaryHandler.DeleteFoodEntry)
+				diary.GET("/summary", diaryRead, diaryHandler.GetDiarySummary)
+				diary.POST("/copy", diaryWrite, requireVerifiedEmail, diaryHandler.CopyDiaryEntries)
+				diary.GET("/goals", diaryRead, goalHandler.ListGoals)
+				diary.PUT("/goals/:date", diaryWrite, requireVerifiedEmail, goalHandler.SetGoal)
+				diary.DELETE("/goals/:date", diaryWrite, requireVerifiedEmail, goalHandler.DeleteGoal)
 			}
 		}
+
+		// Admin routes (require admin access)
+		admin := apiV1.Group("/admin")
+		admin.Use(authMiddleware, requireAccessToken, requireAdmin, rateLimiter.Limit(middleware.RateLimitScopeAPI, middleware.KeyByUser))
+		{
+			admin.GET("/audit-logs", auditHandler.ListAuditLogs)
+			admin.POST("/users/:id/unlock", adminHandler.UnlockUser)
+			admin.PUT("/users/:id/role", adminHandler.UpdateUserRole)
+		}
 	}
 
 	// Create server
@@ -160,6 +263,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	jobs.Stop()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -250,3 +354,112 @@ func runFoodImport(cfg *config.Config) {
 		log.Println("USDA food import completed successfully")
 	}
 }
+
+// promoteUserToAdmin gives the admin role to the user with the given email
+func promoteUserToAdmin(userRepo repository.UserRepository, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := userRepo.GetUserByEmail(ctx, auth.NormalizeEmail(email))
+	if err != nil {
+		return err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return fmt.Errorf("user %s not found", email)
+	}
+
+	if _, err := userRepo.UpdateUserRole(ctx, user.ID, model.RoleAdmin); err != nil {
+		return err
+	}
+
+	log.Printf("User %s promoted to admin", user.Email)
+	return nil
+}
+
+// bootstrapAdmin promotes the configured user to admin while the service has no admin
+func bootstrapAdmin(userRepo repository.UserRepository, cfg *config.Config) {
+	email := cfg.Security.BootstrapAdminEmail
+	if email == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	promoted, err := userRepo.BootstrapAdmin(ctx, auth.NormalizeEmail(email))
+	if err != nil {
+		log.Printf("Admin bootstrap failed: %v", err)
+		return
+	}
+	if promoted {
+		log.Printf("User %s promoted to admin from configuration", email)
+	}
+}
+
+// registerMaintenanceJobs adds the periodic cleanup jobs to the scheduler
+func registerMaintenanceJobs(jobs *scheduler.Scheduler, cfg *config.Config, userRepo repository.UserRepository, tokenRepo repository.TokenRepository, identityRepo repository.IdentityRepository) error {
+	if cfg.Scheduler.TokenPurgeInterval > 0 {
+		err := jobs.Register(scheduler.Job{
+			Name:     "purge_expired_tokens",
+			Interval: cfg.Scheduler.TokenPurgeInterval,
+			Run: func(ctx context.Context) error {
+				return purgeExpiredTokens(ctx, tokenRepo, identityRepo)
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Security.AccountPurgeInterval > 0 {
+		err := jobs.Register(scheduler.Job{
+			Name:     "purge_deleted_accounts",
+			Interval: cfg.Security.AccountPurgeInterval,
+			Run: func(ctx context.Context) error {
+				return purgeDeletedAccounts(ctx, userRepo, cfg.Security.AccountDeletionGracePeriod)
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// purgeExpiredTokens removes expired tokens and abandoned OIDC logins
+func purgeExpiredTokens(ctx context.Context, tokenRepo repository.TokenRepository, identityRepo repository.IdentityRepository) error {
+	now := time.Now()
+
+	result, err := tokenRepo.PurgeExpiredTokens(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	oidcStates, err := identityRepo.PurgeExpiredOIDCStates(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Purged expired tokens: refresh=%d password_reset=%d email_verification=%d revoked_access=%d oidc_states=%d",
+		result.RefreshTokens,
+		result.PasswordResetTokens,
+		result.EmailVerificationTokens,
+		result.RevokedAccessTokens,
+		oidcStates,
+	)
+	return nil
+}
+
+// purgeDeletedAccounts removes accounts whose deletion grace period has passed
+func purgeDeletedAccounts(ctx context.Context, userRepo repository.UserRepository, gracePeriod time.Duration) error {
+	purged, err := userRepo.PurgeDeletedUsers(ctx, time.Now().Add(-gracePeriod))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		log.Printf("Purged %d deleted accounts", purged)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// Event describes an auditable action
+type Event struct {
+	UserID       *uuid.UUID
+	Action       string
+	ResourceType string
+	ResourceID   *uuid.UUID
+	Metadata     map[string]interface{}
+}
+
+// Logger writes audit events to auth.audit_logs
+type Logger struct {
+	repo repository.AuditRepository
+}
+
+// NewLogger creates a new audit Logger
+func NewLogger(repo repository.AuditRepository) *Logger {
+	return &Logger{repo: repo}
+}
+
+// Record writes an audit event for the request. The acting user defaults to the
+// authenticated user. Failures are logged and never fail the request.
+func (l *Logger) Record(c *gin.Context, event Event) {
+	entry := &model.AuditLog{
+		ID:         uuid.New(),
+		UserID:     event.UserID,
+		Action:     event.Action,
+		ResourceID: event.ResourceID,
+		CreatedAt:  time.Now(),
+	}
+
+	if entry.UserID == nil {
+		if userID, ok := userIDFromContext(c); ok {
+			entry.UserID = &userID
+		}
+	}
+	if event.ResourceType != "" {
+		entry.ResourceType = &event.ResourceType
+	}
+	if ip := c.ClientIP(); ip != "" {
+		entry.IPAddress = &ip
+	}
+	if userAgent := c.Request.UserAgent(); userAgent != "" {
+		entry.UserAgent = &userAgent
+	}
+	if len(event.Metadata) > 0 {
+		metadata, err := json.Marshal(event.Metadata)
+		if err != nil {
+			log.Printf("Failed to encode audit metadata for %s: %v", event.Action, err)
+		} else {
+			entry.Metadata = metadata
+		}
+	}
+
+	// Detach from the request so a cancelled client does not drop the record
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), 5*time.Second)
+	defer cancel()
+
+	if err := l.repo.CreateAuditLog(ctx, entry); err != nil {
+		log.Printf("Failed to write audit log for %s: %v", event.Action, err)
+	}
+}
+
+// userIDFromContext reads the authenticated user ID set by auth middleware
+func userIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/yourusername/auth-service/internal/config"
+)
+
+// Supported signing algorithms for access tokens
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// ErrUnknownKeyID is returned when a token references a key that is not configured
+var ErrUnknownKeyID = errors.New("token signed with an unknown key")
+
+// verificationKey is a key accepted when verifying tokens
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// keySet holds the key used to sign new tokens and all keys accepted for verification,
+// indexed by key id. HMAC keys have an empty key id.
+type keySet struct {
+	signingKeyID string
+	signing      verificationKey
+	verification map[string]verificationKey
+}
+
+// newHMACKeySet creates a key set that signs and verifies with a shared secret
+func newHMACKeySet(secret string) *keySet {
+	key := verificationKey{method: jwt.SigningMethodHS256, key: []byte(secret)}
+	return &keySet{
+		signing:      key,
+		verification: map[string]verificationKey{"": key},
+	}
+}
+
+// loadAccessKeySet builds the access token key set from configuration. Asymmetric
+// algorithms load a PEM private key for signing and any additional PEM public keys
+// that remain valid for verification during rotation.
+func loadAccessKeySet(cfg config.JWTConfig) (*keySet, error) {
+	switch cfg.Algorithm {
+	case "", AlgorithmHS256:
+		return newHMACKeySet(cfg.AccessTokenSecret), nil
+	case AlgorithmRS256, AlgorithmEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+
+	if cfg.SigningKeyID == "" || cfg.SigningKeyFile == "" {
+		return nil, fmt.Errorf("jwt.signing_key_id and jwt.signing_key_file are required for %s", cfg.Algorithm)
+	}
+
+	pemData, err := os.ReadFile(cfg.SigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	ks := &keySet{
+		signingKeyID: cfg.SigningKeyID,
+		verification: make(map[string]verificationKey),
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmRS256:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA signing key: %w", err)
+		}
+		ks.signing = verificationKey{method: jwt.SigningMethodRS256, key: privateKey}
+		ks.verification[cfg.SigningKeyID] = verificationKey{method: jwt.SigningMethodRS256, key: &privateKey.PublicKey}
+	case AlgorithmEdDSA:
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Ed25519 signing key: %w", err)
+		}
+		edKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key is not an Ed25519 key")
+		}
+		ks.signing = verificationKey{method: jwt.SigningMethodEdDSA, key: edKey}
+		ks.verification[cfg.SigningKeyID] = verificationKey{method: jwt.SigningMethodEdDSA, key: edKey.Public()}
+	}
+
+	for _, keyCfg := range cfg.VerificationKeys {
+		if _, exists := ks.verification[keyCfg.ID]; exists || keyCfg.ID == "" {
+			return nil, fmt.Errorf("verification key id %q is empty or duplicated", keyCfg.ID)
+		}
+
+		key, err := loadPublicKey(keyCfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load verification key %q: %w", keyCfg.ID, err)
+		}
+		ks.verification[keyCfg.ID] = key
+	}
+
+	return ks, nil
+}
+
+// loadPublicKey reads a PEM encoded RSA or Ed25519 public key
+func loadPublicKey(path string) (verificationKey, error) {
+	pemData, err := os.ReadFile(path)
+	if err != nil {
+		return verificationKey{}, err
+	}
+
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(pemData); err == nil {
+		return verificationKey{method: jwt.SigningMethodRS256, key: rsaKey}, nil
+	}
+
+	edKey, err := jwt.ParseEdPublicKeyFromPEM(pemData)
+	if err != nil {
+		return verificationKey{}, fmt.Errorf("key is neither an RSA nor an Ed25519 public key")
+	}
+	return verificationKey{method: jwt.SigningMethodEdDSA, key: edKey}, nil
+}
+
+// methods returns the algorithms accepted by the key set
+func (ks *keySet) methods() []string {
+	seen := make(map[string]bool)
+	methods := make([]string, 0, 2)
+	for _, key := range ks.verification {
+		alg := key.method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// keyFunc resolves the verification key for a token from its kid header
+func (ks *keySet) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	key, ok := ks.verification[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", t.Method.Alg(), kid)
+	}
+
+	return key.key, nil
+}
+
+// jwks returns the public verification keys as a JSON Web Key Set, ordered by key id
+func (ks *keySet) jwks() *JWKSet {
+	kids := make([]string, 0, len(ks.verification))
+	for kid := range ks.verification {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := &JWKSet{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := ks.verification[kid]
+		switch pub := key.key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, rsaJWK(kid, pub))
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, ed25519JWK(kid, pub))
+		}
+	}
+
+	return set
+}
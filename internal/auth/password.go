@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/yourusername/auth-service/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidPassword is returned when a password does not match its hash
+var ErrInvalidPassword = errors.New("invalid password")
+
+// maxPasswordBytes is the longest input bcrypt accepts
+const maxPasswordBytes = 72
+
+// PasswordPolicyError describes which password policy rules were violated
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+// Error implements the error interface
+func (e *PasswordPolicyError) Error() string {
+	return "password must " + strings.Join(e.Violations, ", ")
+}
+
+// ValidatePassword checks a password against the configured password policy
+func ValidatePassword(cfg config.SecurityConfig, password string) error {
+	var hasUpper, hasLower, hasNumber, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	var violations []string
+	if len([]rune(password)) < cfg.PasswordMinLength {
+		violations = append(violations, fmt.Sprintf("be at least %d characters long", cfg.PasswordMinLength))
+	}
+	// bcrypt limits bytes, not characters, so multibyte passwords hit it sooner
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, fmt.Sprintf("be at most %d bytes long", maxPasswordBytes))
+	}
+	if cfg.PasswordRequireUppercase && !hasUpper {
+		violations = append(violations, "contain an uppercase letter")
+	}
+	if cfg.PasswordRequireLowercase && !hasLower {
+		violations = append(violations, "contain a lowercase letter")
+	}
+	if cfg.PasswordRequireNumbers && !hasNumber {
+		violations = append(violations, "contain a number")
+	}
+	if cfg.PasswordRequireSpecial && !hasSpecial {
+		violations = append(violations, "contain a special character")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// HashPassword hashes a password with bcrypt using the given cost
+func HashPassword(password string, cost int) (string, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// CheckPassword compares a password with its bcrypt hash
+func CheckPassword(hash, password string) error {
+	// Accounts created through an external identity provider have no password
+	if hash == "" {
+		return ErrInvalidPassword
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrInvalidPassword
+		}
+		return fmt.Errorf("failed to check password: %w", err)
+	}
+	return nil
+}
+
+// dummyHashes caches a bcrypt hash per cost for CheckPasswordConstantTime
+var dummyHashes sync.Map
+
+// dummyHash returns a hash of a fixed password created with the given cost
+func dummyHash(cost int) ([]byte, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	if hash, ok := dummyHashes.Load(cost); ok {
+		return hash.([]byte), nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("dummy-password"), cost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dummy password: %w", err)
+	}
+	dummyHashes.Store(cost, hash)
+	return hash, nil
+}
+
+// CheckPasswordConstantTime does the same bcrypt work as CheckPassword for a login
+// without an account, so response times do not reveal which emails are registered.
+// It always returns ErrInvalidPassword unless hashing fails.
+func CheckPasswordConstantTime(password string, cost int) error {
+	hash, err := dummyHash(cost)
+	if err != nil {
+		return err
+	}
+
+	_ = bcrypt.CompareHashAndPassword(hash, []byte(password))
+	return ErrInvalidPassword
+}
+
+// NormalizeEmail lowercases and trims an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/config"
+	"github.com/yourusername/auth-service/internal/model"
+)
+
+// Token verification errors
+var (
+	ErrTokenExpired     = errors.New("token has expired")
+	ErrTokenMalformed   = errors.New("token is malformed or has an invalid signature")
+	ErrInvalidTokenType = errors.New("token has an unexpected type")
+)
+
+// jwtClaims is the JWT representation of model.TokenClaims
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Email     string          `json:"email"`
+	Type      model.TokenType `json:"type"`
+	Role      model.Role      `json:"role,omitempty"`
+	SessionID string          `json:"sid,omitempty"`
+}
+
+// toModel converts JWT claims to model.TokenClaims
+func (c *jwtClaims) toModel() *model.TokenClaims {
+	claims := &model.TokenClaims{
+		TokenID:   c.ID,
+		UserID:    c.Subject,
+		Email:     c.Email,
+		Type:      c.Type,
+		Role:      c.Role,
+		SessionID: c.SessionID,
+	}
+	if c.ExpiresAt != nil {
+		claims.Exp = c.ExpiresAt.Unix()
+	}
+	if c.IssuedAt != nil {
+		claims.Iat = c.IssuedAt.Unix()
+	}
+	return claims
+}
+
+// SignedToken is a signed JWT together with its claims
+type SignedToken struct {
+	Token  string
+	Claims *model.TokenClaims
+}
+
+// TokenManager issues and verifies JWT tokens. Access tokens are signed with the
+// configured algorithm so other services can verify them; refresh and MFA tokens are
+// only read by this service and always use HMAC.
+type TokenManager struct {
+	cfg         config.JWTConfig
+	accessKeys  *keySet
+	refreshKeys *keySet
+}
+
+// NewTokenManager creates a new TokenManager, loading signing keys from disk if needed
+func NewTokenManager(cfg config.JWTConfig) (*TokenManager, error) {
+	accessKeys, err := loadAccessKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenManager{
+		cfg:         cfg,
+		accessKeys:  accessKeys,
+		refreshKeys: newHMACKeySet(cfg.RefreshTokenSecret),
+	}, nil
+}
+
+// JWKS returns the public keys that verify access tokens. It is empty for HMAC.
+func (m *TokenManager) JWKS() *JWKSet {
+	return m.accessKeys.jwks()
+}
+
+// AccessTokenExpiry returns the configured access token lifetime
+func (m *TokenManager) AccessTokenExpiry() time.Duration {
+	return m.cfg.AccessTokenExpiry
+}
+
+// RefreshTokenExpiry returns the configured refresh token lifetime
+func (m *TokenManager) RefreshTokenExpiry() time.Duration {
+	return m.cfg.RefreshTokenExpiry
+}
+
+// GenerateAccessToken signs a new access token for the user bound to a session
+func (m *TokenManager) GenerateAccessToken(user *model.User, sessionID uuid.UUID) (*SignedToken, error) {
+	return m.sign(user, model.TokenTypeAccess, m.accessKeys, m.cfg.AccessTokenExpiry, sessionID.String())
+}
+
+// MFATokenExpiry returns the configured lifetime of MFA challenge tokens
+func (m *TokenManager) MFATokenExpiry() time.Duration {
+	return m.cfg.MFATokenExpiry
+}
+
+// GenerateMFAToken signs a challenge token proving the user passed the password step
+func (m *TokenManager) GenerateMFAToken(user *model.User) (*SignedToken, error) {
+	return m.sign(user, model.TokenTypeMFA, m.refreshKeys, m.cfg.MFATokenExpiry, "")
+}
+
+// GenerateRefreshToken signs a new refresh token for the user
+func (m *TokenManager) GenerateRefreshToken(user *model.User) (*SignedToken, error) {
+	return m.sign(user, model.TokenTypeRefresh, m.refreshKeys, m.cfg.RefreshTokenExpiry, "")
+}
+
+// sign builds and signs a token of the given type
+func (m *TokenManager) sign(user *model.User, tokenType model.TokenType, keys *keySet, expiry time.Duration, sessionID string) (*SignedToken, error) {
+	now := time.Now()
+	claims := &jwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+		},
+		Email:     user.Email,
+		Type:      tokenType,
+		Role:      user.Role,
+		SessionID: sessionID,
+	}
+
+	unsigned := jwt.NewWithClaims(keys.signing.method, claims)
+	if keys.signingKeyID != "" {
+		unsigned.Header["kid"] = keys.signingKeyID
+	}
+
+	token, err := unsigned.SignedString(keys.signing.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign %s token: %w", tokenType, err)
+	}
+
+	return &SignedToken{Token: token, Claims: claims.toModel()}, nil
+}
+
+// ParseAccessToken verifies an access token and returns its claims
+func (m *TokenManager) ParseAccessToken(token string) (*model.TokenClaims, error) {
+	return m.parse(token, model.TokenTypeAccess, m.accessKeys)
+}
+
+// ParseMFAToken verifies an MFA challenge token and returns its claims
+func (m *TokenManager) ParseMFAToken(token string) (*model.TokenClaims, error) {
+	return m.parse(token, model.TokenTypeMFA, m.refreshKeys)
+}
+
+// ParseRefreshToken verifies a refresh token and returns its claims
+func (m *TokenManager) ParseRefreshToken(token string) (*model.TokenClaims, error) {
+	return m.parse(token, model.TokenTypeRefresh, m.refreshKeys)
+}
+
+// parse verifies signature, expiry and type of a token
+func (m *TokenManager) parse(token string, tokenType model.TokenType, keys *keySet) (*model.TokenClaims, error) {
+	var claims jwtClaims
+	_, err := jwt.ParseWithClaims(token, &claims, keys.keyFunc,
+		jwt.WithValidMethods(keys.methods()),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+	}
+
+	if claims.Type != tokenType {
+		return nil, ErrInvalidTokenType
+	}
+	if claims.ID == "" || claims.Subject == "" {
+		return nil, ErrTokenMalformed
+	}
+
+	return claims.toModel(), nil
+}
+
+// GenerateOpaqueToken returns a random URL-safe token for single-use links
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 hex digest of a token for storage
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -13,10 +13,13 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Security SecurityConfig `mapstructure:"security"`
+	Lockout  LockoutConfig  `mapstructure:"lockout"`
+	OIDC     OIDCConfig     `mapstructure:"oidc"`
 	Email    EmailConfig    `mapstructure:"email"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
 	Importer ImporterConfig `mapstructure:"importer"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
 }
 
 // ServerConfig holds server configuration
@@ -26,6 +29,8 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	// Proxies whose X-Forwarded-For is used for the client IP; empty trusts none
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // DatabaseConfig holds database configuration
@@ -48,6 +53,18 @@ type JWTConfig struct {
 	RefreshTokenSecret string        `mapstructure:"refresh_token_secret"`
 	AccessTokenExpiry  time.Duration `mapstructure:"access_token_expiry"`
 	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry"`
+	MFATokenExpiry     time.Duration `mapstructure:"mfa_token_expiry"`
+	// Access token signing: HS256 uses access_token_secret, RS256 and EdDSA use PEM key files
+	Algorithm        string               `mapstructure:"algorithm"`
+	SigningKeyID     string               `mapstructure:"signing_key_id"`
+	SigningKeyFile   string               `mapstructure:"signing_key_file"`
+	VerificationKeys []JWTVerificationKey `mapstructure:"verification_keys"`
+}
+
+// JWTVerificationKey is a public key still accepted for access tokens, e.g. after rotation
+type JWTVerificationKey struct {
+	ID   string `mapstructure:"id"`
+	File string `mapstructure:"file"`
 }
 
 // SecurityConfig holds security configuration
@@ -58,6 +75,46 @@ type SecurityConfig struct {
 	PasswordRequireLowercase bool `mapstructure:"password_require_lowercase"`
 	PasswordRequireNumbers  bool `mapstructure:"password_require_numbers"`
 	PasswordRequireSpecial  bool `mapstructure:"password_require_special"`
+	PasswordResetTokenExpiry time.Duration `mapstructure:"password_reset_token_expiry"`
+	EmailVerificationTokenExpiry    time.Duration `mapstructure:"email_verification_token_expiry"`
+	EmailVerificationResendInterval time.Duration `mapstructure:"email_verification_resend_interval"`
+	RequireVerifiedEmailForDiary    bool          `mapstructure:"require_verified_email_for_diary"`
+	AccountDeletionGracePeriod      time.Duration `mapstructure:"account_deletion_grace_period"`
+	AccountPurgeInterval            time.Duration `mapstructure:"account_purge_interval"`
+	BootstrapAdminEmail             string        `mapstructure:"bootstrap_admin_email"` // promoted to admin on startup while no admin exists
+	MFAIssuer                       string        `mapstructure:"mfa_issuer"`
+	MFARecoveryCodeCount            int           `mapstructure:"mfa_recovery_code_count"`
+}
+
+// LockoutConfig holds account lockout configuration for failed logins
+type LockoutConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	MaxFailedAttempts int           `mapstructure:"max_failed_attempts"`
+	BaseDuration      time.Duration `mapstructure:"base_duration"` // doubled on each consecutive lockout
+	MaxDuration       time.Duration `mapstructure:"max_duration"`
+}
+
+// OIDCConfig holds external OpenID Connect login configuration
+type OIDCConfig struct {
+	StateTTL  time.Duration        `mapstructure:"state_ttl"` // time allowed to complete the provider login
+	Providers []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+// OIDCProviderConfig holds the settings of one OpenID Connect provider
+type OIDCProviderConfig struct {
+	Name         string   `mapstructure:"name"` // used in URLs, e.g. /auth/oidc/{name}/login
+	IssuerURL    string   `mapstructure:"issuer_url"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"` // requested in addition to openid
+}
+
+// SchedulerConfig holds background maintenance job configuration
+type SchedulerConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	Jitter             time.Duration `mapstructure:"jitter"` // random delay added before every run
+	TokenPurgeInterval time.Duration `mapstructure:"token_purge_interval"`
 }
 
 // EmailConfig holds email configuration
@@ -68,6 +125,9 @@ type EmailConfig struct {
 	SMTPUsername string `mapstructure:"smtp_username"`
 	SMTPPassword string `mapstructure:"smtp_password"`
 	FromAddress  string `mapstructure:"from_address"`
+	Backend      string `mapstructure:"backend"`   // smtp, file or stdout
+	FilePath     string `mapstructure:"file_path"` // used by the file backend
+	LinkBaseURL  string `mapstructure:"link_base_url"`
 }
 
 // LoggingConfig holds logging configuration
@@ -82,6 +142,14 @@ type RateLimitConfig struct {
 	Enabled           bool `mapstructure:"enabled"`
 	RequestsPerMinute int  `mapstructure:"requests_per_minute"`
 	Burst             int  `mapstructure:"burst"`
+	// Overrides holds per-route-group limits keyed by group name (e.g. "login")
+	Overrides map[string]RateLimitRule `mapstructure:"overrides"`
+}
+
+// RateLimitRule holds a rate limit for a route group
+type RateLimitRule struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	Burst             int `mapstructure:"burst"`
 }
 
 // ImporterConfig holds USDA food importer configuration
@@ -151,7 +219,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("jwt.access_token_secret", "change-me-in-production")
 	v.SetDefault("jwt.refresh_token_secret", "change-me-in-production-too")
 	v.SetDefault("jwt.access_token_expiry", "15m")
-	v.SetDefault("jwt.refresh_token_expiry", "7d")
+	v.SetDefault("jwt.refresh_token_expiry", "168h")
+	v.SetDefault("jwt.mfa_token_expiry", "5m")
+	v.SetDefault("jwt.algorithm", "HS256")
 
 	// Security defaults
 	v.SetDefault("security.bcrypt_cost", 12)
@@ -160,12 +230,38 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("security.password_require_lowercase", true)
 	v.SetDefault("security.password_require_numbers", true)
 	v.SetDefault("security.password_require_special", true)
+	v.SetDefault("security.password_reset_token_expiry", "30m")
+	v.SetDefault("security.email_verification_token_expiry", "24h")
+	v.SetDefault("security.email_verification_resend_interval", "1m")
+	v.SetDefault("security.require_verified_email_for_diary", false)
+	v.SetDefault("security.account_deletion_grace_period", "720h")
+	v.SetDefault("security.account_purge_interval", "1h")
+	v.SetDefault("security.bootstrap_admin_email", "")
+	v.SetDefault("security.mfa_issuer", "Nutrition Service")
+	v.SetDefault("security.mfa_recovery_code_count", 10)
+
+	// Lockout defaults
+	v.SetDefault("lockout.enabled", true)
+	v.SetDefault("lockout.max_failed_attempts", 5)
+	v.SetDefault("lockout.base_duration", "1m")
+	v.SetDefault("lockout.max_duration", "24h")
+
+	// OIDC defaults
+	v.SetDefault("oidc.state_ttl", "10m")
+
+	// Scheduler defaults
+	v.SetDefault("scheduler.enabled", true)
+	v.SetDefault("scheduler.jitter", "1m")
+	v.SetDefault("scheduler.token_purge_interval", "1h")
 
 	// Email defaults
 	v.SetDefault("email.enabled", false)
 	v.SetDefault("email.smtp_host", "smtp.gmail.com")
 	v.SetDefault("email.smtp_port", 587)
 	v.SetDefault("email.from_address", "noreply@example.com")
+	v.SetDefault("email.backend", "smtp")
+	v.SetDefault("email.file_path", "emails.log")
+	v.SetDefault("email.link_base_url", "http://localhost:3000")
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
@@ -176,6 +272,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("rate_limit.enabled", true)
 	v.SetDefault("rate_limit.requests_per_minute", 60)
 	v.SetDefault("rate_limit.burst", 10)
+	v.SetDefault("rate_limit.overrides.login.requests_per_minute", 5)
+	v.SetDefault("rate_limit.overrides.login.burst", 5)
+	v.SetDefault("rate_limit.overrides.password_reset.requests_per_minute", 3)
+	v.SetDefault("rate_limit.overrides.password_reset.burst", 3)
 
 	// Importer defaults
 	v.SetDefault("importer.enabled", true)
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/audit"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// AdminHandler handles administrative user management HTTP requests
+type AdminHandler struct {
+	userRepo repository.UserRepository
+	auditLog *audit.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(userRepo repository.UserRepository, auditLog *audit.Logger) *AdminHandler {
+	return &AdminHandler{
+		userRepo: userRepo,
+		auditLog: auditLog,
+	}
+}
+
+// UpdateUserRole handles PUT /api/v1/admin/users/{id}/role
+// @Summary Change a user's role
+// @Description Assign the user, dietitian or admin role to a user (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body model.UserRoleUpdate true "New role"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "ID must be a valid UUID",
+		})
+		return
+	}
+
+	var req model.UserRoleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Admins cannot demote themselves and leave the service without an admin
+	currentUserID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+	if currentUserID == userID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "You cannot change your own role",
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "User not found",
+			Message: "User with the specified ID does not exist",
+		})
+		return
+	}
+
+	previousRole := user.Role
+	updated, err := h.userRepo.UpdateUserRole(c.Request.Context(), userID, req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !updated {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "User not found",
+			Message: "User with the specified ID does not exist",
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionRoleChange,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   &userID,
+		Metadata: map[string]interface{}{
+			"from": previousRole,
+			"to":   req.Role,
+		},
+	})
+
+	user.Role = req.Role
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// UnlockUser handles POST /api/v1/admin/users/{id}/unlock
+// @Summary Unlock a user account
+// @Description Clear failed login attempts and any active lockout of a user (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "ID must be a valid UUID",
+		})
+		return
+	}
+
+	unlocked, err := h.userRepo.UnlockUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !unlocked {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "User not found",
+			Message: "User with the specified ID does not exist",
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionAccountUnlock,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   &userID,
+	})
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/audit"
+	"github.com/yourusername/auth-service/internal/auth"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// apiKeyPrefixLength is the number of leading key characters stored for display
+const apiKeyPrefixLength = 12
+
+// APIKeyHandler handles personal access token HTTP requests
+type APIKeyHandler struct {
+	apiKeyRepo repository.APIKeyRepository
+	auditLog   *audit.Logger
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(apiKeyRepo repository.APIKeyRepository, auditLog *audit.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyRepo: apiKeyRepo,
+		auditLog:   auditLog,
+	}
+}
+
+// CreateAPIKey handles POST /api/v1/protected/api-keys
+// @Summary Create an API key
+// @Description Create a scoped API key for integrations. The key is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body model.APIKeyCreate true "API key name, scopes and optional expiry"
+// @Success 201 {object} model.APIKeyCreateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req model.APIKeyCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "expires_at must be in the future",
+		})
+		return
+	}
+
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+	plaintext := model.APIKeyPrefix + secret
+
+	key := &model.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      req.Name,
+		KeyPrefix: plaintext[:apiKeyPrefixLength],
+		KeyHash:   auth.HashToken(plaintext),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := h.apiKeyRepo.CreateAPIKey(c.Request.Context(), key); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionAPIKeyCreate,
+		ResourceType: model.AuditResourceAPIKey,
+		ResourceID:   &key.ID,
+		Metadata: map[string]interface{}{
+			"name":   key.Name,
+			"scopes": key.Scopes,
+		},
+	})
+
+	c.JSON(http.StatusCreated, model.APIKeyCreateResponse{
+		APIKey: *key,
+		Key:    plaintext,
+	})
+}
+
+// ListAPIKeys handles GET /api/v1/protected/api-keys
+// @Summary List API keys
+// @Description List the active API keys of the current user
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} model.APIKey
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	keys, err := h.apiKeyRepo.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey handles DELETE /api/v1/protected/api-keys/{id}
+// @Summary Revoke an API key
+// @Description Revoke one API key of the current user
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid API key ID",
+			Message: "ID must be a valid UUID",
+		})
+		return
+	}
+
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	revoked, err := h.apiKeyRepo.RevokeAPIKey(c.Request.Context(), userID, keyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "API key not found",
+			Message: "Active API key with the specified ID does not exist",
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionAPIKeyRevoke,
+		ResourceType: model.AuditResourceAPIKey,
+		ResourceID:   &keyID,
+	})
+
+	c.Status(http.StatusNoContent)
+}
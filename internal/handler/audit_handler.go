@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditRepo repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{auditRepo: auditRepo}
+}
+
+// ListAuditLogs handles GET /api/v1/admin/audit-logs
+// @Summary Query audit logs
+// @Description List audit log entries filtered by user, action and time range (admin only)
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param action query string false "Action, e.g. login_failed or diary_update"
+// @Param from query string false "Start of time range (RFC 3339)"
+// @Param to query string false "End of time range (RFC 3339)"
+// @Param limit query int false "Number of results per page (default: 50)" default(50)
+// @Param offset query int false "Offset for pagination (default: 0)" default(0)
+// @Success 200 {object} model.AuditLogResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var req model.AuditLogQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request parameters",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+	if req.Limit > 500 {
+		req.Limit = 500
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	filter := &model.AuditLogFilter{
+		Action: req.Action,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	// Query parameters were validated by binding
+	if req.UserID != "" {
+		userID := uuid.MustParse(req.UserID)
+		filter.UserID = &userID
+	}
+	if req.From != "" {
+		from, _ := time.Parse(time.RFC3339, req.From)
+		filter.From = &from
+	}
+	if req.To != "" {
+		to, _ := time.Parse(time.RFC3339, req.To)
+		filter.To = &to
+	}
+
+	logs, total, err := h.auditRepo.ListAuditLogs(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Calculate pagination
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + req.Limit - 1) / req.Limit
+	}
+	page := (req.Offset / req.Limit) + 1
+
+	c.JSON(http.StatusOK, model.AuditLogResponse{
+		Data: logs,
+		Pagination: &model.Pagination{
+			Page:       page,
+			Limit:      req.Limit,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	})
+}
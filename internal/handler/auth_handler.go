@@ -0,0 +1,1199 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/audit"
+	"github.com/yourusername/auth-service/internal/auth"
+	"github.com/yourusername/auth-service/internal/config"
+	"github.com/yourusername/auth-service/internal/mailer"
+	"github.com/yourusername/auth-service/internal/middleware"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// AuthHandler handles authentication-related HTTP requests
+type AuthHandler struct {
+	userRepo     repository.UserRepository
+	tokenRepo    repository.TokenRepository
+	mfaRepo      repository.MFARepository
+	tokenManager *auth.TokenManager
+	mailer       mailer.Mailer
+	auditLog     *audit.Logger
+	security     config.SecurityConfig
+	lockout      config.LockoutConfig
+	email        config.EmailConfig
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, mfaRepo repository.MFARepository, tokenManager *auth.TokenManager, mail mailer.Mailer, auditLog *audit.Logger, security config.SecurityConfig, lockout config.LockoutConfig, email config.EmailConfig) *AuthHandler {
+	return &AuthHandler{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		mfaRepo:      mfaRepo,
+		tokenManager: tokenManager,
+		mailer:       mail,
+		auditLog:     auditLog,
+		security:     security,
+		lockout:      lockout,
+		email:        email,
+	}
+}
+
+// Register handles POST /api/v1/auth/register
+// @Summary Register a new user
+// @Description Create a new user account with email and password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.UserCreate true "Registration data"
+// @Success 201 {object} model.UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req model.UserCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Enforce password policy
+	if err := auth.ValidatePassword(h.security, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Weak password",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(req.Password, h.security.BcryptCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user := &model.User{
+		ID:           uuid.New(),
+		Email:        auth.NormalizeEmail(req.Email),
+		PasswordHash: passwordHash,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		IsActive:     true,
+		IsVerified:   false,
+		Role:         model.RoleUser,
+	}
+
+	// Save to database
+	err = h.userRepo.CreateUser(c.Request.Context(), user)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailAlreadyExists) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Email already registered",
+				Message: "A user with this email already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Send email verification link; the user can request a new one if this fails
+	if err := h.sendVerificationEmail(c.Request.Context(), user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusCreated, user.ToResponse())
+}
+
+// Login handles POST /api/v1/auth/login
+// @Summary Log in
+// @Description Authenticate with email and password and receive a token pair.
+// @Description Accounts with 2FA receive an mfa_required challenge instead; complete it at /api/v1/auth/login/mfa.
+// @Description Repeated failed attempts temporarily lock the account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.UserLogin true "Login credentials"
+// @Success 200 {object} model.TokenPair
+// @Success 200 {object} model.MFAChallenge
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req model.UserLogin
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByEmail(c.Request.Context(), auth.NormalizeEmail(req.Email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Unknown, deleted and wrong-password logins share one response and the same
+	// bcrypt cost, so neither reveals whether the email is registered
+	if user == nil || user.DeletedAt != nil {
+		if err := auth.CheckPasswordConstantTime(req.Password, h.security.BcryptCost); !errors.Is(err, auth.ErrInvalidPassword) {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+		h.recordLoginFailure(c, user, req.Email, "unknown_account")
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid credentials",
+			Message: "Email or password is incorrect",
+		})
+		return
+	}
+
+	// Locked accounts are rejected before the password is checked
+	if h.lockout.Enabled && user.IsLocked(time.Now()) {
+		h.recordLoginFailure(c, user, req.Email, "account_locked")
+		c.Header("Retry-After", fmt.Sprintf("%d", int(time.Until(*user.LockedUntil).Seconds())+1))
+		c.JSON(http.StatusLocked, ErrorResponse{
+			Error:   "Account locked",
+			Message: "Too many failed login attempts, please try again later",
+			Code:    "account_locked",
+		})
+		return
+	}
+
+	if err := auth.CheckPassword(user.PasswordHash, req.Password); err != nil {
+		if errors.Is(err, auth.ErrInvalidPassword) {
+			h.recordLoginFailure(c, user, req.Email, "invalid_password")
+			if err := recordFailedAttempt(c, h.userRepo, h.auditLog, h.lockout, user); err != nil {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{
+					Error:   "Internal server error",
+					Message: err.Error(),
+				})
+				return
+			}
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error:   "Invalid credentials",
+				Message: "Email or password is incorrect",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !user.IsActive {
+		h.recordLoginFailure(c, user, req.Email, "account_disabled")
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Account disabled",
+			Message: "This account has been deactivated",
+		})
+		return
+	}
+
+	h.startSession(c, user, req.DeviceInfo, nil)
+}
+
+// startSession finishes a first-factor login: accounts with 2FA get a challenge token,
+// all others a token pair
+func (h *AuthHandler) startSession(c *gin.Context, user *model.User, deviceInfo *string, metadata map[string]interface{}) {
+	if user.TOTPEnabled {
+		challenge, err := h.tokenManager.GenerateMFAToken(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, model.MFAChallenge{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiresIn:   int64(h.tokenManager.MFATokenExpiry().Seconds()),
+		})
+		return
+	}
+
+	h.completeLogin(c, user, deviceInfo, metadata)
+}
+
+// LoginMFA handles POST /api/v1/auth/login/mfa
+// @Summary Complete a two-factor login
+// @Description Exchange the mfa_token returned by login and a TOTP or recovery code for a token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.MFALoginRequest true "Challenge token and code"
+// @Success 200 {object} model.TokenPair
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req model.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	claims, err := h.tokenManager.ParseMFAToken(req.MFAToken)
+	if err != nil {
+		code := middleware.ErrCodeTokenMalformed
+		if errors.Is(err, auth.ErrTokenExpired) {
+			code = middleware.ErrCodeTokenExpired
+		}
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid MFA token",
+			Message: "MFA token is invalid or has expired, please log in again",
+			Code:    code,
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid MFA token",
+			Message: "MFA token subject is invalid",
+			Code:    middleware.ErrCodeTokenMalformed,
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if user == nil || user.DeletedAt != nil || !user.IsActive || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid MFA token",
+			Message: "MFA token is invalid or has expired, please log in again",
+			Code:    middleware.ErrCodeTokenRevoked,
+		})
+		return
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords
+	if h.lockout.Enabled && user.IsLocked(time.Now()) {
+		h.recordLoginFailure(c, user, user.Email, "account_locked")
+		c.Header("Retry-After", fmt.Sprintf("%d", int(time.Until(*user.LockedUntil).Seconds())+1))
+		c.JSON(http.StatusLocked, ErrorResponse{
+			Error:   "Account locked",
+			Message: "Too many failed login attempts, please try again later",
+			Code:    "account_locked",
+		})
+		return
+	}
+
+	method, err := verifySecondFactor(c.Request.Context(), h.mfaRepo, user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if method == "" {
+		h.recordLoginFailure(c, user, user.Email, "invalid_mfa_code")
+		if err := recordFailedAttempt(c, h.userRepo, h.auditLog, h.lockout, user); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid code",
+			Message: "The code is incorrect or has expired",
+		})
+		return
+	}
+
+	h.completeLogin(c, user, req.DeviceInfo, map[string]interface{}{
+		"mfa_method": method,
+	})
+}
+
+// completeLogin opens a new session for an authenticated user and responds with a token pair.
+// metadata is added to the login audit event.
+func (h *AuthHandler) completeLogin(c *gin.Context, user *model.User, deviceInfo *string, metadata map[string]interface{}) {
+	if deviceInfo == nil {
+		userAgent := c.Request.UserAgent()
+		deviceInfo = &userAgent
+	}
+
+	tokens, refreshRecord, err := h.newTokenPair(user, deviceInfo, c.ClientIP(), nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.tokenRepo.CreateRefreshToken(c.Request.Context(), refreshRecord); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.userRepo.UpdateLastLogin(c.Request.Context(), user.ID, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["device_info"] = deviceInfo
+
+	h.auditLog.Record(c, audit.Event{
+		UserID:       &user.ID,
+		Action:       model.AuditActionLogin,
+		ResourceType: model.AuditResourceSession,
+		ResourceID:   &refreshRecord.FamilyID,
+		Metadata:     metadata,
+	})
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// recordLoginFailure writes a failed login to the audit log
+func (h *AuthHandler) recordLoginFailure(c *gin.Context, user *model.User, email, reason string) {
+	event := audit.Event{
+		Action:       model.AuditActionLoginFailed,
+		ResourceType: model.AuditResourceUser,
+		Metadata: map[string]interface{}{
+			"email":  auth.NormalizeEmail(email),
+			"reason": reason,
+		},
+	}
+	if user != nil {
+		event.UserID = &user.ID
+		event.ResourceID = &user.ID
+	}
+
+	h.auditLog.Record(c, event)
+}
+
+// recordFailedAttempt counts a wrong password or code against the account and audits a
+// resulting lockout
+func recordFailedAttempt(c *gin.Context, userRepo repository.UserRepository, auditLog *audit.Logger, lockout config.LockoutConfig, user *model.User) error {
+	if !lockout.Enabled {
+		return nil
+	}
+
+	lockedUntil, err := userRepo.RecordFailedLogin(c.Request.Context(), user.ID, lockout)
+	if err != nil {
+		return err
+	}
+
+	if lockedUntil != nil {
+		auditLog.Record(c, audit.Event{
+			UserID:       &user.ID,
+			Action:       model.AuditActionAccountLocked,
+			ResourceType: model.AuditResourceUser,
+			ResourceID:   &user.ID,
+			Metadata: map[string]interface{}{
+				"locked_until": lockedUntil.UTC(),
+			},
+		})
+	}
+
+	return nil
+}
+
+// Refresh handles POST /api/v1/auth/refresh
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair. Each refresh token can be used once;
+// @Description presenting an already rotated token revokes every token issued from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} model.TokenPair
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	claims, err := h.tokenManager.ParseRefreshToken(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenExpired) {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error:   "Invalid refresh token",
+				Code:    middleware.ErrCodeTokenExpired,
+				Message: "Refresh token has expired",
+			})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid refresh token",
+			Code:    middleware.ErrCodeTokenMalformed,
+			Message: "Refresh token is invalid",
+		})
+		return
+	}
+
+	stored, err := h.tokenRepo.GetRefreshTokenByHash(c.Request.Context(), auth.HashToken(req.RefreshToken))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if stored == nil || stored.UserID.String() != claims.UserID {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid refresh token",
+			Code:    middleware.ErrCodeTokenMalformed,
+			Message: "Refresh token is not recognized",
+		})
+		return
+	}
+
+	if stored.RevokedAt != nil {
+		// A rotated token being presented again means it was stolen
+		if stored.ReplacedBy != nil {
+			h.rejectReusedRefreshToken(c, stored)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid refresh token",
+			Code:    middleware.ErrCodeTokenRevoked,
+			Message: "Refresh token has been revoked",
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), stored.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if user == nil || !user.IsActive || user.DeletedAt != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Invalid refresh token",
+			Code:    middleware.ErrCodeUserInactive,
+			Message: "User account is inactive or deleted",
+		})
+		return
+	}
+
+	tokens, refreshRecord, err := h.newTokenPair(user, stored.DeviceInfo, c.ClientIP(), &stored.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err = h.tokenRepo.RotateRefreshToken(c.Request.Context(), stored.ID, refreshRecord)
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyRotated) {
+			h.rejectReusedRefreshToken(c, stored)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// rejectReusedRefreshToken revokes the whole token family of a reused refresh token
+func (h *AuthHandler) rejectReusedRefreshToken(c *gin.Context, token *model.RefreshToken) {
+	if err := h.tokenRepo.RevokeRefreshTokenFamily(c.Request.Context(), token.FamilyID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		UserID:       &token.UserID,
+		Action:       model.AuditActionRefreshTokenReuse,
+		ResourceType: model.AuditResourceSession,
+		ResourceID:   &token.FamilyID,
+		Metadata: map[string]interface{}{
+			"refresh_token_id": token.ID,
+			"device_info":      token.DeviceInfo,
+		},
+	})
+
+	c.JSON(http.StatusUnauthorized, ErrorResponse{
+		Error:   "Invalid refresh token",
+		Code:    middleware.ErrCodeTokenReused,
+		Message: "Refresh token was already used; all sessions for this device have been revoked",
+	})
+}
+
+// newTokenPair signs a new access/refresh token pair and builds the refresh token record.
+// A nil familyID starts a new refresh token family.
+func (h *AuthHandler) newTokenPair(user *model.User, deviceInfo *string, ipAddress string, familyID *uuid.UUID) (*model.TokenPair, *model.RefreshToken, error) {
+	refreshToken, err := h.tokenManager.GenerateRefreshToken(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshID, err := uuid.Parse(refreshToken.Claims.TokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	family := refreshID
+	if familyID != nil {
+		family = *familyID
+	}
+
+	accessToken, err := h.tokenManager.GenerateAccessToken(user, family)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ip *string
+	if ipAddress != "" {
+		ip = &ipAddress
+	}
+
+	record := &model.RefreshToken{
+		ID:         refreshID,
+		UserID:     user.ID,
+		TokenHash:  auth.HashToken(refreshToken.Token),
+		DeviceInfo: deviceInfo,
+		IPAddress:  ip,
+		FamilyID:   family,
+		ExpiresAt:  time.Unix(refreshToken.Claims.Exp, 0),
+		CreatedAt:  time.Now(),
+	}
+
+	pair := &model.TokenPair{
+		AccessToken:  accessToken.Token,
+		RefreshToken: refreshToken.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.tokenManager.AccessTokenExpiry().Seconds()),
+	}
+
+	return pair, record, nil
+}
+
+// Logout handles POST /api/v1/auth/logout
+// @Summary Log out
+// @Description Revoke the current access token and the refresh token of this session
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, err := getClaimsFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.revokeCurrentAccessToken(c, claims, model.RevokeReasonLogout); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if claims.SessionID != "" {
+		sessionID, err := uuid.Parse(claims.SessionID)
+		if err == nil {
+			err = h.tokenRepo.RevokeRefreshTokenFamily(c.Request.Context(), sessionID)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	event := audit.Event{
+		Action:       model.AuditActionLogout,
+		ResourceType: model.AuditResourceSession,
+		Metadata: map[string]interface{}{
+			"access_token_id": claims.TokenID,
+		},
+	}
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		event.ResourceID = &sessionID
+	}
+	h.auditLog.Record(c, event)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
+	})
+}
+
+// LogoutAll handles POST /api/v1/auth/logout-all
+// @Summary Log out from all devices
+// @Description Revoke the current access token and every refresh token of the user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	claims, err := getClaimsFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.revokeCurrentAccessToken(c, claims, model.RevokeReasonLogoutAll); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Access tokens of other sessions stop working once their session is revoked
+	userID, err := uuid.Parse(claims.UserID)
+	if err == nil {
+		err = h.tokenRepo.RevokeAllUserRefreshTokens(c.Request.Context(), userID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionLogoutAll,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   &userID,
+		Metadata: map[string]interface{}{
+			"access_token_id": claims.TokenID,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out from all devices",
+	})
+}
+
+// ListSessions handles GET /api/v1/protected/sessions
+// @Summary List active sessions
+// @Description List the devices the current user is logged in on
+// @Tags auth
+// @Produce json
+// @Success 200 {array} model.Session
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	claims, err := getClaimsFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sessions, err := h.tokenRepo.ListActiveSessions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID.String() == claims.SessionID
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession handles DELETE /api/v1/protected/sessions/{id}
+// @Summary Revoke a session
+// @Description Log out one specific device of the current user
+// @Tags auth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid session ID",
+			Message: "ID must be a valid UUID",
+		})
+		return
+	}
+
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	revoked, err := h.tokenRepo.RevokeUserSession(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Session not found",
+			Message: "Active session with the specified ID does not exist",
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionSessionRevoke,
+		ResourceType: model.AuditResourceSession,
+		ResourceID:   &sessionID,
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordReset handles POST /api/v1/auth/password-reset-request
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.PasswordResetRequest true "Account email"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/password-reset-request [post]
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var req model.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByEmail(c.Request.Context(), auth.NormalizeEmail(req.Email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Only active accounts get a link, but the response never reveals which case applied
+	if user != nil && user.IsActive && user.DeletedAt == nil {
+		token, err := auth.GenerateOpaqueToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		err = h.tokenRepo.CreatePasswordResetToken(c.Request.Context(), &model.PasswordResetToken{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			TokenHash: auth.HashToken(token),
+			ExpiresAt: time.Now().Add(h.security.PasswordResetTokenExpiry),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		link := h.buildLink("/reset-password", token)
+		h.sendEmailAsync(mailer.PasswordResetMessage(user.Email, link, h.security.PasswordResetTokenExpiry))
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account with this email exists, a password reset link has been sent",
+	})
+}
+
+// ConfirmPasswordReset handles POST /api/v1/auth/password-reset-confirm
+// @Summary Confirm a password reset
+// @Description Set a new password using a reset token. All sessions of the user are logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.PasswordResetConfirm true "Reset token and new password"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/password-reset-confirm [post]
+func (h *AuthHandler) ConfirmPasswordReset(c *gin.Context) {
+	var req model.PasswordResetConfirm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Enforce password policy
+	if err := auth.ValidatePassword(h.security, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Weak password",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(req.Password, h.security.BcryptCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, err := h.tokenRepo.ResetPassword(c.Request.Context(), auth.HashToken(req.Token), passwordHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if userID == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid reset token",
+			Message: "Password reset token is invalid, expired or already used",
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		UserID:       userID,
+		Action:       model.AuditActionPasswordChange,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   userID,
+		Metadata: map[string]interface{}{
+			"method": "password_reset",
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password has been reset successfully",
+	})
+}
+
+// VerifyEmail handles POST /api/v1/auth/verify-email
+// @Summary Verify email address
+// @Description Confirm the email address using the token from the verification link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.EmailVerificationConfirm true "Verification token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req model.EmailVerificationConfirm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, err := h.tokenRepo.VerifyEmail(c.Request.Context(), auth.HashToken(req.Token))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if userID == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid verification token",
+			Message: "Email verification token is invalid, expired or already used",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerificationEmail handles POST /api/v1/auth/verify-email/resend
+// @Summary Resend verification email
+// @Description Send a new email verification link to the current user
+// @Tags auth
+// @Produce json
+// @Success 202 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not found",
+		})
+		return
+	}
+
+	if user.IsVerified {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Already verified",
+			Message: "Email address is already verified",
+		})
+		return
+	}
+
+	// Throttle resends per user
+	lastSentAt, err := h.tokenRepo.GetLastEmailVerificationSentAt(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if lastSentAt != nil {
+		if wait := h.security.EmailVerificationResendInterval - time.Since(*lastSentAt); wait > 0 {
+			c.Header("Retry-After", fmt.Sprintf("%d", int(wait.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{
+				Error:   "Too many requests",
+				Message: "Verification email was sent recently, please try again later",
+			})
+			return
+		}
+	}
+
+	if err := h.sendVerificationEmail(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Verification email has been sent",
+	})
+}
+
+// sendVerificationEmail issues a verification token and emails the link to the user
+func (h *AuthHandler) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = h.tokenRepo.CreateEmailVerificationToken(ctx, &model.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(h.security.EmailVerificationTokenExpiry),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := h.buildLink("/verify-email", token)
+	h.sendEmailAsync(mailer.EmailVerificationMessage(user.Email, link, h.security.EmailVerificationTokenExpiry))
+	return nil
+}
+
+// buildLink builds a client app link carrying a single-use token
+func (h *AuthHandler) buildLink(path, token string) string {
+	return strings.TrimRight(h.email.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendEmailAsync sends an email in the background so response timing does not depend on delivery
+func (h *AuthHandler) sendEmailAsync(msg *mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := h.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send %q email: %v", msg.Subject, err)
+		}
+	}()
+}
+
+// JWKS handles GET /.well-known/jwks.json
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens. Empty when tokens are signed with a shared HMAC secret.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.tokenManager.JWKS())
+}
+
+// revokeCurrentAccessToken adds the access token of the request to the revocation list
+func (h *AuthHandler) revokeCurrentAccessToken(c *gin.Context, claims *model.TokenClaims, reason string) error {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid token subject: %w", err)
+	}
+
+	return h.tokenRepo.RevokeAccessToken(c.Request.Context(), &model.RevokedAccessToken{
+		ID:        uuid.New(),
+		TokenID:   claims.TokenID,
+		UserID:    userID,
+		ExpiresAt: time.Unix(claims.Exp, 0),
+		RevokedAt: time.Now(),
+		Reason:    &reason,
+	})
+}
+
+// getClaimsFromContext extracts token claims from Gin context (set by auth middleware)
+func getClaimsFromContext(c *gin.Context) (*model.TokenClaims, error) {
+	claimsVal, exists := c.Get("claims")
+	if !exists {
+		return nil, fmt.Errorf("token claims not found in context")
+	}
+
+	claims, ok := claimsVal.(*model.TokenClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims type in context")
+	}
+
+	return claims, nil
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/audit"
 	"github.com/yourusername/auth-service/internal/model"
 	"github.com/yourusername/auth-service/internal/repository"
 )
@@ -15,24 +17,29 @@ import (
 type DiaryHandler struct {
 	diaryRepo repository.DiaryRepository
 	foodRepo  repository.FoodRepository
+	goalRepo  repository.GoalRepository
+	auditLog  *audit.Logger
 }
 
 // NewDiaryHandler creates a new DiaryHandler
-func NewDiaryHandler(diaryRepo repository.DiaryRepository, foodRepo repository.FoodRepository) *DiaryHandler {
+func NewDiaryHandler(diaryRepo repository.DiaryRepository, foodRepo repository.FoodRepository, goalRepo repository.GoalRepository, auditLog *audit.Logger) *DiaryHandler {
 	return &DiaryHandler{
 		diaryRepo: diaryRepo,
 		foodRepo:  foodRepo,
+		goalRepo:  goalRepo,
+		auditLog:  auditLog,
 	}
 }
 
 // GetDiaryEntries handles GET /api/v1/diary/entries
 // @Summary Get diary entries for a period
-// @Description Get food entries for a user within a date period
+// @Description Get food entries for a user within a date period. Each day summary includes progress towards the goal active on that day.
 // @Tags diary
 // @Accept json
 // @Produce json
 // @Param date query string true "Base date (YYYY-MM-DD)"
-// @Param daysCount query int false "Number of days to include (default: 1)" default(1)
+// @Param daysCount query int false "Number of days to include, 1-366 (default: 1)" default(1)
+// @Param nutrients query string false "Nutrient totals in summaries: none, core, extended or all (default: none)" Enums(none, core, extended, all)
 // @Success 200 {object} model.DiaryPeriodResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -81,6 +88,16 @@ func (h *DiaryHandler) GetDiaryEntries(c *gin.Context) {
 		return
 	}
 
+	// Get goals active during the period
+	goals, err := h.goalRepo.GetGoalsForPeriod(c.Request.Context(), userID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	// Organize entries by date and meal type
 	daysMap := make(map[string]*model.DiaryDay)
 	mealTypes := model.MealTypes()
@@ -107,22 +124,22 @@ func (h *DiaryHandler) GetDiaryEntries(c *gin.Context) {
 		}
 	}
 
-	// Convert map to sorted slice and calculate summaries
+	// Calculate summaries for all days at once
+	summaries, err := h.getDailySummaries(c, userID, startDate, endDate, req.Nutrients, goals)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Convert map to sorted slice
 	var days []*model.DiaryDay
 	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
 		dateStr := d.Format("2006-01-02")
 		day := daysMap[dateStr]
-		
-		// Calculate summary for the day
-		summary, err := h.diaryRepo.GetDaySummary(c.Request.Context(), userID, d)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Internal server error",
-				Message: err.Error(),
-			})
-			return
-		}
-		day.Summary = summary
+		day.Summary = summaries[dateStr]
 		
 		days = append(days, day)
 	}
@@ -194,6 +211,9 @@ func (h *DiaryHandler) CreateFoodEntry(c *gin.Context) {
 
 	// Calculate nutrients
 	var calculatedCalories, calculatedProtein, calculatedFat, calculatedCarbs *float64
+	var foodDescription *string
+	var nutrientsPer100g []*model.EntryNutrient
+	var nutrientsOverridden bool
 	
 	if req.FDCID != nil {
 		// Get food from USDA database and calculate nutrients
@@ -214,21 +234,35 @@ func (h *DiaryHandler) CreateFoodEntry(c *gin.Context) {
 			return
 		}
 		
-		// Calculate nutrients based on amount_grams
-		// This is a simplified calculation - in reality you'd need to find
-		// the specific nutrients (calories, protein, fat, carbs) from the nutrients list
-		// For now, we'll use custom values if provided, otherwise set to nil
-		if req.CustomCalories != nil {
-			calculatedCalories = req.CustomCalories
-		}
-		if req.CustomProtein != nil {
-			calculatedProtein = req.CustomProtein
-		}
-		if req.CustomFat != nil {
-			calculatedFat = req.CustomFat
-		}
-		if req.CustomCarbs != nil {
-			calculatedCarbs = req.CustomCarbs
+		// Keep a snapshot of the food so later USDA imports never change this entry
+		foodDescription = &foodWithNutrients.Food.Description
+		nutrientsPer100g = model.NewEntryNutrients(foodWithNutrients.Nutrients)
+
+		// Calculate nutrients from the per-100 g USDA values scaled to amount_grams
+		macros := foodWithNutrients.Profile.MacrosFor(req.AmountGrams)
+		calculatedCalories = macros.Calories
+		calculatedProtein = macros.Protein
+		calculatedFat = macros.Fat
+		calculatedCarbs = macros.Carbs
+
+		// Custom values replace calculated ones only when explicitly requested
+		if req.OverrideNutrients {
+			if req.CustomCalories != nil {
+				calculatedCalories = req.CustomCalories
+				nutrientsOverridden = true
+			}
+			if req.CustomProtein != nil {
+				calculatedProtein = req.CustomProtein
+				nutrientsOverridden = true
+			}
+			if req.CustomFat != nil {
+				calculatedFat = req.CustomFat
+				nutrientsOverridden = true
+			}
+			if req.CustomCarbs != nil {
+				calculatedCarbs = req.CustomCarbs
+				nutrientsOverridden = true
+			}
 		}
 	} else {
 		// Custom food - use provided custom values
@@ -240,18 +274,21 @@ func (h *DiaryHandler) CreateFoodEntry(c *gin.Context) {
 
 	// Create food entry
 	entry := &model.FoodEntry{
-		ID:                 uuid.New(),
-		UserID:             userID,
-		Date:               date,
-		MealType:           req.MealType,
-		FDCID:              req.FDCID,
-		CustomFoodName:     req.CustomFoodName,
-		AmountGrams:        req.AmountGrams,
-		CalculatedCalories: calculatedCalories,
-		CalculatedProtein:  calculatedProtein,
-		CalculatedFat:      calculatedFat,
-		CalculatedCarbs:    calculatedCarbs,
-		CreatedAt:          time.Now(),
+		ID:                  uuid.New(),
+		UserID:              userID,
+		Date:                date,
+		MealType:            req.MealType,
+		FDCID:               req.FDCID,
+		CustomFoodName:      req.CustomFoodName,
+		AmountGrams:         req.AmountGrams,
+		CalculatedCalories:  calculatedCalories,
+		CalculatedProtein:   calculatedProtein,
+		CalculatedFat:       calculatedFat,
+		CalculatedCarbs:     calculatedCarbs,
+		FoodDescription:     foodDescription,
+		NutrientsPer100g:    nutrientsPer100g,
+		NutrientsOverridden: nutrientsOverridden,
+		CreatedAt:           time.Now(),
 	}
 
 	// Save to database
@@ -264,6 +301,15 @@ func (h *DiaryHandler) CreateFoodEntry(c *gin.Context) {
 		return
 	}
 
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionDiaryCreate,
+		ResourceType: model.AuditResourceFoodEntry,
+		ResourceID:   &entry.ID,
+		Metadata: map[string]interface{}{
+			"after": entry,
+		},
+	})
+
 	c.JSON(http.StatusCreated, entry)
 }
 
@@ -367,6 +413,16 @@ func (h *DiaryHandler) UpdateFoodEntry(c *gin.Context) {
 		return
 	}
 
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionDiaryUpdate,
+		ResourceType: model.AuditResourceFoodEntry,
+		ResourceID:   &entryID,
+		Metadata: map[string]interface{}{
+			"before": existingEntry,
+			"after":  updatedEntry,
+		},
+	})
+
 	c.JSON(http.StatusOK, updatedEntry)
 }
 
@@ -450,17 +506,27 @@ func (h *DiaryHandler) DeleteFoodEntry(c *gin.Context) {
 		return
 	}
 
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionDiaryDelete,
+		ResourceType: model.AuditResourceFoodEntry,
+		ResourceID:   &entryID,
+		Metadata: map[string]interface{}{
+			"before": existingEntry,
+		},
+	})
+
 	c.Status(http.StatusNoContent)
 }
 
 // GetDiarySummary handles GET /api/v1/diary/summary
 // @Summary Get diary summary for a period
-// @Description Get nutritional summary for a user within a date period
+// @Description Get nutritional summary for a user within a date period, with progress towards the goals active on its days
 // @Tags diary
 // @Accept json
 // @Produce json
 // @Param date query string true "Base date (YYYY-MM-DD)"
-// @Param daysCount query int false "Number of days to include (default: 1)" default(1)
+// @Param daysCount query int false "Number of days to include, 1-366 (default: 1)" default(1)
+// @Param nutrients query string false "Nutrient totals in summaries: none, core, extended or all (default: none)" Enums(none, core, extended, all)
 // @Success 200 {object} model.DiarySummaryResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -500,7 +566,7 @@ func (h *DiaryHandler) GetDiarySummary(c *gin.Context) {
 	}
 
 	// Get period summary
-	summary, err := h.diaryRepo.GetPeriodSummary(c.Request.Context(), userID, startDate, endDate)
+	summary, err := h.diaryRepo.GetPeriodSummary(c.Request.Context(), userID, startDate, endDate, req.Nutrients)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Internal server error",
@@ -509,6 +575,40 @@ func (h *DiaryHandler) GetDiarySummary(c *gin.Context) {
 		return
 	}
 
+	// Judge each day against the goal active on it
+	goals, err := h.goalRepo.GetGoalsForPeriod(c.Request.Context(), userID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if len(goals) > 0 {
+		summaries, err := h.getDailySummaries(c, userID, startDate, endDate, model.NutrientFieldsNone, goals)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		progress := &model.GoalProgress{}
+		for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+			daySummary := summaries[d.Format("2006-01-02")]
+			if daySummary.Goal == nil {
+				continue
+			}
+			progress.Add(model.ActiveGoal(goals, d), daySummary)
+		}
+
+		if progress.DaysWithGoal > 0 {
+			summary.Goal = progress
+		}
+	}
+
 	// Build response
 	response := model.DiarySummaryResponse{
 		Period: struct {
@@ -574,6 +674,16 @@ func (h *DiaryHandler) CopyDiaryEntries(c *gin.Context) {
 		return
 	}
 
+	// Capture target day before it is overwritten
+	replacedEntries, err := h.diaryRepo.GetFoodEntriesByPeriod(c.Request.Context(), userID, targetDate, targetDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	// Copy entries
 	err = h.diaryRepo.CopyFoodEntries(c.Request.Context(), userID, sourceDate, targetDate)
 	if err != nil {
@@ -584,6 +694,26 @@ func (h *DiaryHandler) CopyDiaryEntries(c *gin.Context) {
 		return
 	}
 
+	metadata := map[string]interface{}{
+		"source_date": req.SourceDate,
+		"target_date": req.TargetDate,
+		"before":      replacedEntries,
+	}
+
+	// The copy is already committed, so a failed re-read must not fail the request
+	copiedEntries, err := h.diaryRepo.GetFoodEntriesByPeriod(c.Request.Context(), userID, targetDate, targetDate)
+	if err != nil {
+		log.Printf("Failed to load copied diary entries for audit of user %s: %v", userID, err)
+	} else {
+		metadata["after"] = copiedEntries
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionDiaryCopy,
+		ResourceType: model.AuditResourceFoodEntry,
+		Metadata:     metadata,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Diary entries copied successfully",
 		"source_date": req.SourceDate,
@@ -591,6 +721,45 @@ func (h *DiaryHandler) CopyDiaryEntries(c *gin.Context) {
 	})
 }
 
+// getDailySummaries loads the summary of every day in a period, keyed by date
+// (YYYY-MM-DD), with the requested nutrient totals and progress towards the goal
+// active on each day. Goals must be sorted by EffectiveFrom.
+func (h *DiaryHandler) getDailySummaries(c *gin.Context, userID uuid.UUID, startDate, endDate time.Time, nutrients model.NutrientFields, goals []*model.NutritionGoal) (map[string]*model.DaySummary, error) {
+	// Micronutrient targets need the totals of their nutrients
+	fields := nutrients
+	for _, goal := range goals {
+		if len(goal.Micronutrients) > 0 {
+			fields = model.NutrientFieldsAll
+			break
+		}
+	}
+
+	summaries, err := h.diaryRepo.GetDailySummaries(c.Request.Context(), userID, startDate, endDate, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		dateStr := d.Format("2006-01-02")
+		summary, ok := summaries[dateStr]
+		if !ok {
+			summary = &model.DaySummary{}
+			summaries[dateStr] = summary
+		}
+
+		if goal := model.ActiveGoal(goals, d); goal != nil {
+			summary.Goal = &model.GoalProgress{}
+			summary.Goal.Add(goal, summary)
+		}
+
+		if fields != nutrients {
+			summary.Nutrients = model.FilterNutrientTotals(summary.Nutrients, nutrients)
+		}
+	}
+
+	return summaries, nil
+}
+
 // getUserIDFromContext extracts user ID from Gin context (set by auth middleware)
 func getUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
 	userIDVal, exists := c.Get("user_id")
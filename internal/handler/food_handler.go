@@ -27,6 +27,7 @@ func NewFoodHandler(foodRepo repository.FoodRepository) *FoodHandler {
 // @Param q query string true "Search query"
 // @Param limit query int false "Number of results per page (default: 20)" default(20)
 // @Param offset query int false "Offset for pagination (default: 0)" default(0)
+// @Param fields query string false "Nutrients per food: none, core, extended or all (default: all)" Enums(none, core, extended, all)
 // @Success 200 {object} model.SearchFoodResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -59,9 +60,12 @@ func (h *FoodHandler) SearchFoods(c *gin.Context) {
 	if req.Offset < 0 {
 		req.Offset = 0
 	}
+	if req.Fields == "" {
+		req.Fields = model.NutrientFieldsAll
+	}
 
 	// Search foods
-	foods, total, err := h.foodRepo.SearchFoods(c.Request.Context(), req.Query, req.Limit, req.Offset)
+	foods, total, err := h.foodRepo.SearchFoods(c.Request.Context(), req.Query, req.Limit, req.Offset, req.Fields)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Internal server error",
@@ -136,5 +140,6 @@ func (h *FoodHandler) GetFoodByID(c *gin.Context) {
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/audit"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// GoalHandler handles nutrition goal HTTP requests
+type GoalHandler struct {
+	goalRepo repository.GoalRepository
+	auditLog *audit.Logger
+}
+
+// NewGoalHandler creates a new GoalHandler
+func NewGoalHandler(goalRepo repository.GoalRepository, auditLog *audit.Logger) *GoalHandler {
+	return &GoalHandler{
+		goalRepo: goalRepo,
+		auditLog: auditLog,
+	}
+}
+
+// ListGoals handles GET /api/v1/protected/diary/goals
+// @Summary List nutrition goals
+// @Description List the goal history of the current user, oldest first. Each goal applies from effective_from until the next one.
+// @Tags goals
+// @Produce json
+// @Success 200 {array} model.NutritionGoal
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/diary/goals [get]
+func (h *GoalHandler) ListGoals(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	goals, err := h.goalRepo.ListGoals(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+// SetGoal handles PUT /api/v1/protected/diary/goals/{date}
+// @Summary Set a nutrition goal
+// @Description Set the goal that applies from the given date until the next goal, replacing a goal already starting on that date
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param date path string true "Effective from date (YYYY-MM-DD)"
+// @Param request body model.NutritionGoalUpdate true "Daily targets"
+// @Success 200 {object} model.NutritionGoal
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/diary/goals/{date} [put]
+func (h *GoalHandler) SetGoal(c *gin.Context) {
+	effectiveFrom, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Date must be in YYYY-MM-DD format",
+		})
+		return
+	}
+
+	var req model.NutritionGoalUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := validateGoal(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	goal := &model.NutritionGoal{
+		ID:             uuid.New(),
+		UserID:         userID,
+		EffectiveFrom:  effectiveFrom,
+		Calories:       req.Calories,
+		Protein:        req.Protein,
+		Fat:            req.Fat,
+		Carbs:          req.Carbs,
+		Micronutrients: req.Micronutrients,
+	}
+
+	if err := h.goalRepo.SetGoal(c.Request.Context(), goal); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionGoalSet,
+		ResourceType: model.AuditResourceNutritionGoal,
+		ResourceID:   &goal.ID,
+		Metadata: map[string]interface{}{
+			"after": goal,
+		},
+	})
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DeleteGoal handles DELETE /api/v1/protected/diary/goals/{date}
+// @Summary Delete a nutrition goal
+// @Description Delete the goal starting on the given date; the previous goal then applies until the next one
+// @Tags goals
+// @Produce json
+// @Param date path string true "Effective from date (YYYY-MM-DD)"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/diary/goals/{date} [delete]
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	effectiveFrom, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Date must be in YYYY-MM-DD format",
+		})
+		return
+	}
+
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.goalRepo.DeleteGoal(c.Request.Context(), userID, effectiveFrom)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if goal == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Goal not found",
+			Message: "No goal starts on the specified date",
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionGoalDelete,
+		ResourceType: model.AuditResourceNutritionGoal,
+		ResourceID:   &goal.ID,
+		Metadata: map[string]interface{}{
+			"before": goal,
+		},
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// validateGoal checks the rules binding tags cannot express
+func validateGoal(req *model.NutritionGoalUpdate) error {
+	if req.Calories == nil && req.Protein == nil && req.Fat == nil && req.Carbs == nil && len(req.Micronutrients) == 0 {
+		return fmt.Errorf("at least one target must be set")
+	}
+
+	macros := []struct {
+		name   string
+		target *model.MacroTarget
+	}{
+		{"protein", req.Protein},
+		{"fat", req.Fat},
+		{"carbs", req.Carbs},
+	}
+
+	totalPercent := 0.0
+	for _, macro := range macros {
+		name, target := macro.name, macro.target
+		if target == nil {
+			continue
+		}
+		if (target.Grams == nil) == (target.Percent == nil) {
+			return fmt.Errorf("%s target must set exactly one of grams or percent", name)
+		}
+		if target.Percent != nil {
+			if req.Calories == nil {
+				return fmt.Errorf("%s target in percent requires a calorie target", name)
+			}
+			totalPercent += *target.Percent
+		}
+	}
+	if totalPercent > 100 {
+		return fmt.Errorf("macro targets add up to more than 100%% of energy")
+	}
+
+	seen := make(map[int]bool, len(req.Micronutrients))
+	for _, target := range req.Micronutrients {
+		if seen[target.NutrientID] {
+			return fmt.Errorf("nutrient %d has more than one target", target.NutrientID)
+		}
+		seen[target.NutrientID] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,403 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/auth-service/internal/audit"
+	"github.com/yourusername/auth-service/internal/auth"
+	"github.com/yourusername/auth-service/internal/config"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// Second factor methods recorded in the audit log
+const (
+	mfaMethodTOTP         = "totp"
+	mfaMethodRecoveryCode = "recovery_code"
+)
+
+// MFAHandler handles two-factor authentication enrollment HTTP requests
+type MFAHandler struct {
+	userRepo repository.UserRepository
+	mfaRepo  repository.MFARepository
+	auditLog *audit.Logger
+	security config.SecurityConfig
+	lockout  config.LockoutConfig
+}
+
+// NewMFAHandler creates a new MFAHandler
+func NewMFAHandler(userRepo repository.UserRepository, mfaRepo repository.MFARepository, auditLog *audit.Logger, security config.SecurityConfig, lockout config.LockoutConfig) *MFAHandler {
+	return &MFAHandler{
+		userRepo: userRepo,
+		mfaRepo:  mfaRepo,
+		auditLog: auditLog,
+		security: security,
+		lockout:  lockout,
+	}
+}
+
+// EnrollTOTP handles POST /api/v1/protected/mfa/totp/enroll
+// @Summary Start TOTP enrollment
+// @Description Generate a TOTP secret and otpauth:// URI for an authenticator app. 2FA is enabled only after the first code is confirmed.
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} model.TOTPEnrollment
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/mfa/totp/enroll [post]
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Two-factor authentication already enabled",
+			Message: "Disable two-factor authentication before enrolling a new authenticator",
+		})
+		return
+	}
+
+	secret, uri, err := auth.GenerateTOTPKey(h.security.MFAIssuer, user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.mfaRepo.SetPendingTOTPSecret(c.Request.Context(), user.ID, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: uri,
+	})
+}
+
+// ConfirmTOTP handles POST /api/v1/protected/mfa/totp/confirm
+// @Summary Confirm TOTP enrollment
+// @Description Verify the first code from the authenticator app, enable 2FA and return recovery codes. The codes are shown only once.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body model.MFACodeRequest true "TOTP code"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Two-factor authentication already enabled",
+			Message: "Two-factor authentication is already enabled for this account",
+		})
+		return
+	}
+
+	if user.TOTPSecret == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Enrollment not started",
+			Message: "Call POST /api/v1/protected/mfa/totp/enroll first",
+		})
+		return
+	}
+
+	valid, err := useTOTPCode(c.Request.Context(), h.mfaRepo, user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid code",
+			Message: "The code is incorrect or has expired",
+		})
+		return
+	}
+
+	codes, hashes, err := h.generateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.mfaRepo.EnableTOTP(c.Request.Context(), user.ID, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionMFAEnable,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   &user.ID,
+	})
+
+	c.JSON(http.StatusOK, model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP handles POST /api/v1/protected/mfa/totp/disable
+// @Summary Disable TOTP
+// @Description Turn off two-factor authentication after verifying a TOTP or recovery code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body model.MFACodeRequest true "TOTP or recovery code"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/mfa/totp/disable [post]
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Two-factor authentication not enabled",
+			Message: "Two-factor authentication is not enabled for this account",
+		})
+		return
+	}
+
+	if h.rejectLocked(c, user) {
+		return
+	}
+
+	method, err := verifySecondFactor(c.Request.Context(), h.mfaRepo, user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+	if method == "" {
+		h.rejectInvalidCode(c, user)
+		return
+	}
+
+	if err := h.mfaRepo.DisableTOTP(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionMFADisable,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   &user.ID,
+		Metadata: map[string]interface{}{
+			"method": method,
+		},
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes handles POST /api/v1/protected/mfa/recovery-codes
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after verifying a TOTP code. The new codes are shown only once.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body model.MFACodeRequest true "TOTP code"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	if !user.TOTPEnabled || user.TOTPSecret == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Two-factor authentication not enabled",
+			Message: "Two-factor authentication is not enabled for this account",
+		})
+		return
+	}
+
+	if h.rejectLocked(c, user) {
+		return
+	}
+
+	valid, err := useTOTPCode(c.Request.Context(), h.mfaRepo, user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !valid {
+		h.rejectInvalidCode(c, user)
+		return
+	}
+
+	codes, hashes, err := h.generateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.mfaRepo.ReplaceRecoveryCodes(c.Request.Context(), user.ID, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// generateRecoveryCodes creates recovery codes and their hashes for storage
+func (h *MFAHandler) generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(h.security.MFARecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+
+	return codes, hashes, nil
+}
+
+// rejectLocked responds with 423 when repeated wrong codes or passwords locked the account
+func (h *MFAHandler) rejectLocked(c *gin.Context, user *model.User) bool {
+	if !h.lockout.Enabled || !user.IsLocked(time.Now()) {
+		return false
+	}
+
+	c.Header("Retry-After", fmt.Sprintf("%d", int(time.Until(*user.LockedUntil).Seconds())+1))
+	c.JSON(http.StatusLocked, ErrorResponse{
+		Error:   "Account locked",
+		Message: "Too many failed login attempts, please try again later",
+		Code:    "account_locked",
+	})
+	return true
+}
+
+// rejectInvalidCode counts a wrong code towards the login lockout and responds with 400
+func (h *MFAHandler) rejectInvalidCode(c *gin.Context, user *model.User) {
+	if err := recordFailedAttempt(c, h.userRepo, h.auditLog, h.lockout, user); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   "Invalid code",
+		Message: "The code is incorrect or has expired",
+	})
+}
+
+// useTOTPCode checks a TOTP code and marks its time step as used, so each code is
+// accepted only once
+func useTOTPCode(ctx context.Context, mfaRepo repository.MFARepository, user *model.User, code string) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+
+	step, valid := auth.ValidateTOTPCode(*user.TOTPSecret, code)
+	if !valid {
+		return false, nil
+	}
+
+	return mfaRepo.UseTOTPStep(ctx, user.ID, step)
+}
+
+// verifySecondFactor checks a TOTP code and falls back to consuming a recovery code.
+// It returns the method that matched, or an empty string if the code is invalid.
+func verifySecondFactor(ctx context.Context, mfaRepo repository.MFARepository, user *model.User, code string) (string, error) {
+	valid, err := useTOTPCode(ctx, mfaRepo, user, code)
+	if err != nil {
+		return "", err
+	}
+	if valid {
+		return mfaMethodTOTP, nil
+	}
+
+	used, err := mfaRepo.UseRecoveryCode(ctx, user.ID, auth.HashRecoveryCode(code))
+	if err != nil {
+		return "", err
+	}
+	if used {
+		return mfaMethodRecoveryCode, nil
+	}
+
+	return "", nil
+}
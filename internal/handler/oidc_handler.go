@@ -0,0 +1,633 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/audit"
+	"github.com/yourusername/auth-service/internal/auth"
+	"github.com/yourusername/auth-service/internal/config"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/oidc"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// The browser binding cookie is only sent to the provider callbacks
+const (
+	oidcBindingCookie     = "oidc_binding"
+	oidcBindingCookiePath = "/api/v1/auth/oidc"
+)
+
+// OIDCHandler handles external OpenID Connect login and identity linking HTTP requests
+type OIDCHandler struct {
+	authHandler  *AuthHandler
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+	registry     *oidc.Registry
+	auditLog     *audit.Logger
+	cfg          config.OIDCConfig
+}
+
+// NewOIDCHandler creates a new OIDCHandler. Sessions are issued through authHandler so
+// external logins follow the same 2FA and session rules as password logins.
+func NewOIDCHandler(authHandler *AuthHandler, userRepo repository.UserRepository, identityRepo repository.IdentityRepository, registry *oidc.Registry, auditLog *audit.Logger, cfg config.OIDCConfig) *OIDCHandler {
+	return &OIDCHandler{
+		authHandler:  authHandler,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		registry:     registry,
+		auditLog:     auditLog,
+		cfg:          cfg,
+	}
+}
+
+// Login handles GET /api/v1/auth/oidc/{provider}/login
+// @Summary Log in with an identity provider
+// @Description Redirect the browser to the provider's authorization endpoint (authorization code flow with PKCE)
+// @Tags auth
+// @Param provider path string true "Provider name"
+// @Success 302
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /api/v1/auth/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, ok := h.beginAuthorization(c, c.Param("provider"), nil, nil)
+	if !ok {
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /api/v1/auth/oidc/{provider}/callback
+// @Summary Identity provider callback
+// @Description Complete a provider login or account link. Logins return a token pair (or an mfa_required challenge);
+// @Description links return the linked identity. Unknown identities create a new account unless the email is already registered.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} model.TokenPair
+// @Success 201 {object} model.Identity
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Provider login failed",
+			Message: providerErr + ": " + c.Query("error_description"),
+		})
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "code and state are required",
+		})
+		return
+	}
+
+	// The state must come back to the browser that started the flow, otherwise an
+	// attacker could send a victim their own authorization URL
+	binding, err := c.Cookie(oidcBindingCookie)
+	h.clearBindingCookie(c)
+	if err != nil || binding == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid state",
+			Message: "Login request was not started in this browser, please start again",
+		})
+		return
+	}
+
+	pending, err := h.identityRepo.ConsumeOIDCState(c.Request.Context(), auth.HashToken(state), auth.HashToken(binding))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if pending == nil || pending.Provider != provider {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid state",
+			Message: "Login request is unknown or has expired, please start again",
+		})
+		return
+	}
+
+	external, err := h.registry.Exchange(c.Request.Context(), provider, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Provider login failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if pending.UserID != nil {
+		if !h.linkSessionActive(c, pending) {
+			return
+		}
+		h.linkIdentity(c, *pending.UserID, provider, external)
+		return
+	}
+
+	h.loginWithIdentity(c, provider, external)
+}
+
+// loginWithIdentity signs in the user linked to an external identity, registering a new
+// account on first login
+func (h *OIDCHandler) loginWithIdentity(c *gin.Context, provider string, external *oidc.Identity) {
+	identity, err := h.identityRepo.GetIdentity(c.Request.Context(), external.Issuer, external.Subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user *model.User
+	if identity != nil {
+		user, err = h.userRepo.GetUserByID(c.Request.Context(), identity.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if user == nil || user.DeletedAt != nil {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error:   "Invalid credentials",
+				Message: "The linked account no longer exists",
+			})
+			return
+		}
+	} else {
+		user, identity = h.registerWithIdentity(c, provider, external)
+		if user == nil {
+			return
+		}
+	}
+
+	if !user.IsActive {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Account disabled",
+			Message: "This account has been deactivated",
+		})
+		return
+	}
+
+	// Provider logins must not bypass a lockout caused by wrong passwords or codes
+	if h.authHandler.lockout.Enabled && user.IsLocked(time.Now()) {
+		h.authHandler.recordLoginFailure(c, user, user.Email, "account_locked")
+		c.Header("Retry-After", fmt.Sprintf("%d", int(time.Until(*user.LockedUntil).Seconds())+1))
+		c.JSON(http.StatusLocked, ErrorResponse{
+			Error:   "Account locked",
+			Message: "Too many failed login attempts, please try again later",
+			Code:    "account_locked",
+		})
+		return
+	}
+
+	if err := h.identityRepo.UpdateIdentityLastLogin(c.Request.Context(), identity.ID, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userAgent := c.Request.UserAgent()
+	h.authHandler.startSession(c, user, &userAgent, map[string]interface{}{
+		"method":   "oidc",
+		"provider": provider,
+	})
+}
+
+// registerWithIdentity creates a password-less account for a new external identity.
+// Existing accounts are never linked by email alone; their owner has to link the
+// provider while logged in. It writes an error response and returns nil on failure.
+func (h *OIDCHandler) registerWithIdentity(c *gin.Context, provider string, external *oidc.Identity) (*model.User, *model.Identity) {
+	if external.Email == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Email required",
+			Message: "The identity provider did not return an email address",
+		})
+		return nil, nil
+	}
+
+	email := auth.NormalizeEmail(external.Email)
+	existing, err := h.userRepo.GetUserByEmail(c.Request.Context(), email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return nil, nil
+	}
+
+	if existing != nil {
+		h.respondEmailTaken(c)
+		return nil, nil
+	}
+
+	now := time.Now()
+	user := &model.User{
+		ID:         uuid.New(),
+		Email:      email,
+		IsActive:   true,
+		IsVerified: external.EmailVerified,
+		Role:       model.RoleUser,
+	}
+	identity := &model.Identity{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Provider:    provider,
+		Issuer:      external.Issuer,
+		Subject:     external.Subject,
+		Email:       &email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+
+	if err := h.identityRepo.CreateUserWithIdentity(c.Request.Context(), user, identity); err != nil {
+		if errors.Is(err, repository.ErrEmailAlreadyExists) || errors.Is(err, repository.ErrIdentityAlreadyLinked) {
+			h.respondEmailTaken(c)
+			return nil, nil
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return nil, nil
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		UserID:       &user.ID,
+		Action:       model.AuditActionIdentityLink,
+		ResourceType: model.AuditResourceIdentity,
+		ResourceID:   &identity.ID,
+		Metadata: map[string]interface{}{
+			"provider":     provider,
+			"registration": true,
+		},
+	})
+
+	return user, identity
+}
+
+// respondEmailTaken rejects a provider login whose email belongs to an existing account
+func (h *OIDCHandler) respondEmailTaken(c *gin.Context) {
+	c.JSON(http.StatusConflict, ErrorResponse{
+		Error:   "Account already exists",
+		Message: "An account with this email already exists. Log in and link the provider from your account settings.",
+	})
+}
+
+// linkSessionActive checks that the session which started a link is still logged in
+// and its user still active. It writes an error response and returns false otherwise.
+func (h *OIDCHandler) linkSessionActive(c *gin.Context, pending *model.OIDCState) bool {
+	active := false
+	if pending.SessionID != nil {
+		var err error
+		active, err = h.authHandler.tokenRepo.IsSessionActive(c.Request.Context(), *pending.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return false
+		}
+	}
+
+	if active {
+		user, err := h.userRepo.GetUserByID(c.Request.Context(), *pending.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return false
+		}
+		active = user != nil && user.IsActive && user.DeletedAt == nil
+	}
+
+	if !active {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Session expired",
+			Message: "The session that started linking has ended, please log in and start again",
+		})
+		return false
+	}
+
+	return true
+}
+
+// linkIdentity attaches an external identity to the user who started the link
+func (h *OIDCHandler) linkIdentity(c *gin.Context, userID uuid.UUID, provider string, external *oidc.Identity) {
+	existing, err := h.identityRepo.GetIdentity(c.Request.Context(), external.Issuer, external.Subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if existing != nil {
+		if existing.UserID == userID {
+			c.JSON(http.StatusOK, existing)
+			return
+		}
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Identity already linked",
+			Message: "This provider account is linked to another user",
+		})
+		return
+	}
+
+	identity := &model.Identity{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Provider:  provider,
+		Issuer:    external.Issuer,
+		Subject:   external.Subject,
+		CreatedAt: time.Now(),
+	}
+	if external.Email != "" {
+		email := auth.NormalizeEmail(external.Email)
+		identity.Email = &email
+	}
+
+	if err := h.identityRepo.CreateIdentity(c.Request.Context(), identity); err != nil {
+		if errors.Is(err, repository.ErrIdentityAlreadyLinked) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Identity already linked",
+				Message: "This provider account is linked to another user",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		UserID:       &userID,
+		Action:       model.AuditActionIdentityLink,
+		ResourceType: model.AuditResourceIdentity,
+		ResourceID:   &identity.ID,
+		Metadata: map[string]interface{}{
+			"provider": provider,
+		},
+	})
+
+	c.JSON(http.StatusCreated, identity)
+}
+
+// StartLink handles POST /api/v1/protected/identities/{provider}
+// @Summary Link an identity provider
+// @Description Start linking a provider to the current account. Call this from the browser that will open
+// @Description the returned URL: the response sets a cookie the callback requires to complete the link.
+// @Tags identities
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} model.OIDCAuthorizationResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /api/v1/protected/identities/{provider} [post]
+func (h *OIDCHandler) StartLink(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	claims, err := getClaimsFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Linking requires a token issued for a login session",
+		})
+		return
+	}
+
+	authURL, ok := h.beginAuthorization(c, c.Param("provider"), &userID, &sessionID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, model.OIDCAuthorizationResponse{AuthorizationURL: authURL})
+}
+
+// ListIdentities handles GET /api/v1/protected/identities
+// @Summary List linked identities
+// @Description List the identity providers linked to the current account
+// @Tags identities
+// @Produce json
+// @Success 200 {array} model.Identity
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/identities [get]
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	identities, err := h.identityRepo.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// Unlink handles DELETE /api/v1/protected/identities/{id}
+// @Summary Unlink an identity provider
+// @Description Remove a linked identity. The last identity of an account without a password cannot be removed.
+// @Tags identities
+// @Produce json
+// @Param id path string true "Identity ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/identities/{id} [delete]
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	identityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid identity ID",
+			Message: "ID must be a valid UUID",
+		})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	// Keep at least one way to log in
+	if !user.HasPassword() {
+		identities, err := h.identityRepo.ListIdentities(c.Request.Context(), user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal server error",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if len(identities) <= 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Cannot unlink last login method",
+				Message: "Set a password with the password reset flow before unlinking your only identity provider",
+			})
+			return
+		}
+	}
+
+	deleted, err := h.identityRepo.DeleteIdentity(c.Request.Context(), user.ID, identityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Identity not found",
+			Message: "Linked identity with the specified ID does not exist",
+		})
+		return
+	}
+
+	h.auditLog.Record(c, audit.Event{
+		Action:       model.AuditActionIdentityUnlink,
+		ResourceType: model.AuditResourceIdentity,
+		ResourceID:   &identityID,
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// beginAuthorization stores a pending authorization request bound to this browser and
+// returns the provider URL. It writes an error response and returns false on failure.
+func (h *OIDCHandler) beginAuthorization(c *gin.Context, provider string, userID, sessionID *uuid.UUID) (string, bool) {
+	if !h.registry.Has(provider) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Provider not found",
+			Message: "Identity provider is not configured",
+		})
+		return "", false
+	}
+
+	state, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return "", false
+	}
+
+	binding, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return "", false
+	}
+
+	request, err := h.registry.AuthCodeURL(c.Request.Context(), provider, state)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, ErrorResponse{
+			Error:   "Provider unavailable",
+			Message: err.Error(),
+		})
+		return "", false
+	}
+
+	now := time.Now()
+	pending := &model.OIDCState{
+		ID:           uuid.New(),
+		StateHash:    auth.HashToken(state),
+		BindingHash:  auth.HashToken(binding),
+		Provider:     provider,
+		CodeVerifier: request.CodeVerifier,
+		Nonce:        request.Nonce,
+		UserID:       userID,
+		SessionID:    sessionID,
+		ExpiresAt:    now.Add(h.cfg.StateTTL),
+		CreatedAt:    now,
+	}
+
+	if err := h.identityRepo.CreateOIDCState(c.Request.Context(), pending); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return "", false
+	}
+
+	h.setBindingCookie(c, binding, int(h.cfg.StateTTL.Seconds()))
+
+	return request.URL, true
+}
+
+// setBindingCookie stores the browser binding of a pending authorization request
+func (h *OIDCHandler) setBindingCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcBindingCookie, value, maxAge, oidcBindingCookiePath, "", true, true)
+}
+
+// clearBindingCookie removes the browser binding once the callback has used it
+func (h *OIDCHandler) clearBindingCookie(c *gin.Context) {
+	h.setBindingCookie(c, "", -1)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
+)
+
+// UserHandler handles current-user profile HTTP requests
+type UserHandler struct {
+	userRepo repository.UserRepository
+}
+
+// NewUserHandler creates a new UserHandler
+func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
+	return &UserHandler{userRepo: userRepo}
+}
+
+// GetMe handles GET /api/v1/protected/me
+// @Summary Get current user
+// @Description Get the profile of the authenticated user
+// @Tags users
+// @Produce json
+// @Success 200 {object} model.UserResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// UpdateMe handles PATCH /api/v1/protected/me
+// @Summary Update current user
+// @Description Update profile fields of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body model.UserUpdate true "Profile fields to update"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/me [patch]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var req model.UserUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Deactivation would lock the user out; account removal goes through DELETE
+	if req.IsActive != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "is_active cannot be changed here; use DELETE /api/v1/protected/me to delete the account",
+		})
+		return
+	}
+
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.userRepo.UpdateUser(c.Request.Context(), userID, &req); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// DeleteMe handles DELETE /api/v1/protected/me
+// @Summary Delete current user
+// @Description Delete the account of the authenticated user and revoke all tokens.
+// @Description The account and its diary are purged permanently after a grace period.
+// @Tags users
+// @Produce json
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/protected/me [delete]
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Access tokens stop working because AuthMiddleware rejects deleted users
+	if err := h.userRepo.SoftDeleteUser(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadCurrentUser loads the authenticated user, writing an error response on failure
+func loadCurrentUser(c *gin.Context, userRepo repository.UserRepository) (*model.User, bool) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	user, err := userRepo.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not found",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
@@ -106,13 +106,17 @@ func (i *Importer) createTables() error {
 		`DROP TABLE IF EXISTS input_foods CASCADE`,
 		`DROP TABLE IF EXISTS foods CASCADE`,
 
+		// Trigram matching used by food search
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public`,
+
 		// Create tables
 		`CREATE TABLE foods (
 			fdc_id INTEGER PRIMARY KEY,
 			description TEXT NOT NULL,
 			data_type TEXT,
 			food_class TEXT,
-			publication_date TEXT
+			publication_date TEXT,
+			search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', description)) STORED
 		)`,
 
 		`CREATE TABLE input_foods (
@@ -168,6 +172,8 @@ func (i *Importer) createTables() error {
 		`CREATE INDEX idx_food_nutrients_fdc ON food_nutrients(fdc_id)`,
 		`CREATE INDEX idx_food_nutrients_nutrient ON food_nutrients(nutrient_id)`,
 		`CREATE INDEX idx_foods_description ON foods(description)`,
+		`CREATE INDEX idx_foods_search_vector ON foods USING GIN (search_vector)`,
+		`CREATE INDEX idx_foods_description_trgm ON foods USING GIN (description public.gin_trgm_ops)`,
 		`CREATE INDEX idx_food_portions_fdc ON food_portions(fdc_id)`,
 		`CREATE INDEX idx_food_attributes_fdc ON food_attributes(fdc_id)`,
 	}
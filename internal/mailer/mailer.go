@@ -0,0 +1,145 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/auth-service/internal/config"
+)
+
+// Supported mailer backends
+const (
+	BackendSMTP   = "smtp"
+	BackendFile   = "file"
+	BackendStdout = "stdout"
+)
+
+// Message represents an outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates a mailer for the backend selected in the email configuration
+func New(cfg config.EmailConfig) (Mailer, error) {
+	if !cfg.Enabled {
+		return &noopMailer{}, nil
+	}
+
+	switch cfg.Backend {
+	case BackendSMTP, "":
+		return &smtpMailer{cfg: cfg}, nil
+	case BackendFile:
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("email.file_path is required for the file backend")
+		}
+		return &writerMailer{from: cfg.FromAddress, open: func() (io.WriteCloser, error) {
+			return os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		}}, nil
+	case BackendStdout:
+		return &writerMailer{from: cfg.FromAddress, open: func() (io.WriteCloser, error) {
+			return nopCloser{os.Stdout}, nil
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown email backend %q", cfg.Backend)
+	}
+}
+
+// smtpMailer sends emails through an SMTP server
+type smtpMailer struct {
+	cfg config.EmailConfig
+}
+
+// Send delivers the message via SMTP
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	addr := fmt.Sprintf("%s:%d", m.cfg.SMTPHost, m.cfg.SMTPPort)
+
+	var auth smtp.Auth
+	if m.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, m.cfg.SMTPHost)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, m.cfg.FromAddress, []string{msg.To}, formatMessage(m.cfg.FromAddress, msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// writerMailer writes emails to a file or stdout, for local development and tests
+type writerMailer struct {
+	from string
+	open func() (io.WriteCloser, error)
+	mu   sync.Mutex
+}
+
+// Send writes the message to the configured sink
+func (m *writerMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, err := m.open()
+	if err != nil {
+		return fmt.Errorf("failed to open email sink: %w", err)
+	}
+	defer w.Close()
+
+	if _, err := fmt.Fprintf(w, "%s\n%s\n\n", formatMessage(m.from, msg), strings.Repeat("-", 72)); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	return nil
+}
+
+// noopMailer discards emails when sending is disabled
+type noopMailer struct{}
+
+// Send logs that an email was skipped
+func (m *noopMailer) Send(ctx context.Context, msg *Message) error {
+	log.Printf("Email sending disabled, skipping %q to %s", msg.Subject, msg.To)
+	return nil
+}
+
+// formatMessage renders a plain-text RFC 5322 message
+func formatMessage(from string, msg *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// nopCloser wraps a writer that must not be closed
+type nopCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (nopCloser) Close() error { return nil }
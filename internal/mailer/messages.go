@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"fmt"
+	"time"
+)
+
+// PasswordResetMessage builds the email with a password reset link
+func PasswordResetMessage(to, link string, expiry time.Duration) *Message {
+	return &Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"We received a request to reset your password.\n\n"+
+				"Open the link below to choose a new one:\n%s\n\n"+
+				"The link expires in %s and can be used once. "+
+				"If you did not request a reset, you can ignore this email.\n",
+			link, expiry,
+		),
+	}
+}
+
+// EmailVerificationMessage builds the email with an email verification link
+func EmailVerificationMessage(to, link string, expiry time.Duration) *Message {
+	return &Message{
+		To:      to,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Thanks for signing up!\n\n"+
+				"Open the link below to verify your email address:\n%s\n\n"+
+				"The link expires in %s. "+
+				"If you did not create an account, you can ignore this email.\n",
+			link, expiry,
+		),
+	}
+}
@@ -1,15 +1,35 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/auth"
+	"github.com/yourusername/auth-service/internal/model"
+	"github.com/yourusername/auth-service/internal/repository"
 )
 
-// AuthMiddleware is a placeholder authentication middleware
-// In a real application, this would validate JWT tokens or session cookies
-func AuthMiddleware() gin.HandlerFunc {
+// Error codes returned by AuthMiddleware and token endpoints
+const (
+	ErrCodeTokenMissing      = "token_missing"
+	ErrCodeTokenMalformed    = "token_malformed"
+	ErrCodeTokenExpired      = "token_expired"
+	ErrCodeTokenRevoked      = "token_revoked"
+	ErrCodeTokenReused       = "token_reused"
+	ErrCodeUserInactive      = "user_inactive"
+	ErrCodeEmailUnverified   = "email_unverified"
+	ErrCodeInsufficientScope = "insufficient_scope"
+	ErrCodeAPIKeyNotAllowed  = "api_key_not_allowed"
+	ErrCodeInsufficientRole  = "insufficient_role"
+)
+
+// AuthMiddleware validates bearer access tokens or API keys and sets the authenticated
+// user in the context. Requests authenticated with an API key also carry the key under "api_key".
+func AuthMiddleware(tokenManager *auth.TokenManager, userRepo repository.UserRepository, tokenRepo repository.TokenRepository, apiKeyRepo repository.APIKeyRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip authentication for health check endpoint
 		if c.Request.URL.Path == "/health" {
@@ -20,45 +40,245 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Unauthorized",
-				"message": "Authorization header is required",
-			})
-			c.Abort()
+			abortUnauthorized(c, ErrCodeTokenMissing, "Authorization header is required")
 			return
 		}
 
 		// Check if it's a Bearer token
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Unauthorized",
-				"message": "Invalid authorization format. Expected: Bearer <token>",
-			})
-			c.Abort()
+			abortUnauthorized(c, ErrCodeTokenMalformed, "Invalid authorization format. Expected: Bearer <token>")
 			return
 		}
 
 		token := parts[1]
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Unauthorized",
-				"message": "Token is empty",
+			abortUnauthorized(c, ErrCodeTokenMissing, "Token is empty")
+			return
+		}
+
+		// API keys are opaque and looked up by hash
+		if strings.HasPrefix(token, model.APIKeyPrefix) {
+			authenticateAPIKey(c, token, userRepo, apiKeyRepo)
+			return
+		}
+
+		// Verify signature, expiry and token type
+		claims, err := tokenManager.ParseAccessToken(token)
+		if err != nil {
+			if errors.Is(err, auth.ErrTokenExpired) {
+				abortUnauthorized(c, ErrCodeTokenExpired, "Token has expired")
+				return
+			}
+			abortUnauthorized(c, ErrCodeTokenMalformed, "Token is invalid")
+			return
+		}
+
+		userID, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			abortUnauthorized(c, ErrCodeTokenMalformed, "Token subject is invalid")
+			return
+		}
+
+		// Reject revoked tokens
+		revoked, err := tokenRepo.IsAccessTokenRevoked(c.Request.Context(), claims.TokenID)
+		if err != nil {
+			abortInternalError(c, err)
+			return
+		}
+		if revoked {
+			abortUnauthorized(c, ErrCodeTokenRevoked, "Token has been revoked")
+			return
+		}
+
+		// Reject tokens whose session was logged out
+		if claims.SessionID != "" {
+			sessionID, err := uuid.Parse(claims.SessionID)
+			if err != nil {
+				abortUnauthorized(c, ErrCodeTokenMalformed, "Token session is invalid")
+				return
+			}
+			active, err := tokenRepo.IsSessionActive(c.Request.Context(), sessionID)
+			if err != nil {
+				abortInternalError(c, err)
+				return
+			}
+			if !active {
+				abortUnauthorized(c, ErrCodeTokenRevoked, "Session has been revoked")
+				return
+			}
+		}
+
+		// Reject inactive or deleted users
+		user, err := userRepo.GetUserByID(c.Request.Context(), userID)
+		if err != nil {
+			abortInternalError(c, err)
+			return
+		}
+		if user == nil || !user.IsActive || user.DeletedAt != nil {
+			abortUnauthorized(c, ErrCodeUserInactive, "User account is inactive or deleted")
+			return
+		}
+
+		c.Set("user_id", userID.String())
+		c.Set("user", user)
+		c.Set("token", token)
+		c.Set("claims", claims)
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey validates an API key and sets its owner in the context
+func authenticateAPIKey(c *gin.Context, token string, userRepo repository.UserRepository, apiKeyRepo repository.APIKeyRepository) {
+	key, err := apiKeyRepo.GetAPIKeyByHash(c.Request.Context(), auth.HashToken(token))
+	if err != nil {
+		abortInternalError(c, err)
+		return
+	}
+	if key == nil {
+		abortUnauthorized(c, ErrCodeTokenMalformed, "API key is invalid")
+		return
+	}
+
+	now := time.Now()
+	if !key.IsUsable(now) {
+		abortUnauthorized(c, ErrCodeTokenRevoked, "API key has been revoked or has expired")
+		return
+	}
+
+	// Reject inactive or deleted users
+	user, err := userRepo.GetUserByID(c.Request.Context(), key.UserID)
+	if err != nil {
+		abortInternalError(c, err)
+		return
+	}
+	if user == nil || !user.IsActive || user.DeletedAt != nil {
+		abortUnauthorized(c, ErrCodeUserInactive, "User account is inactive or deleted")
+		return
+	}
+
+	if err := apiKeyRepo.UpdateAPIKeyLastUsed(c.Request.Context(), key.ID, now); err != nil {
+		abortInternalError(c, err)
+		return
+	}
+
+	c.Set("user_id", key.UserID.String())
+	c.Set("user", user)
+	c.Set("api_key", key)
+
+	c.Next()
+}
+
+// RequireScope rejects API keys that do not grant scope. Requests authenticated with an
+// access token are not restricted. It must run after AuthMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyVal, exists := c.Get("api_key")
+		if !exists {
+			c.Next()
+			return
+		}
+
+		key, ok := keyVal.(*model.APIKey)
+		if !ok || !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"code":    ErrCodeInsufficientScope,
+				"message": "API key does not grant the " + scope + " scope",
 			})
 			c.Abort()
 			return
 		}
 
-		// TODO: In a real application, validate the JWT token here
-		// For now, we'll just accept any non-empty token
-		// This is a temporary implementation until auth service is complete
+		c.Next()
+	}
+}
 
-		// Set user ID in context (placeholder)
-		// In real implementation, extract user ID from token
-		// For now, use a fixed UUID for testing
-		c.Set("user_id", "123e4567-e89b-12d3-a456-426614174000")
-		c.Set("token", token)
+// RequireAccessToken rejects requests authenticated with an API key, for account and
+// session management that must not be reachable by integrations. It must run after AuthMiddleware.
+func RequireAccessToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("api_key"); exists {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"code":    ErrCodeAPIKeyNotAllowed,
+				"message": "This endpoint requires an access token",
+			})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// RequireVerifiedEmail rejects users whose email is not verified. It must run after
+// AuthMiddleware and does nothing when enabled is false.
+func RequireVerifiedEmail(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		userVal, exists := c.Get("user")
+		user, ok := userVal.(*model.User)
+		if !exists || !ok || !user.IsVerified {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"code":    ErrCodeEmailUnverified,
+				"message": "Email address must be verified to perform this action",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole allows only users with one of the given roles. The role is read from the
+// user loaded by AuthMiddleware, so role changes apply without waiting for tokens to expire.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...model.Role) gin.HandlerFunc {
+	allowed := make(map[model.Role]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		userVal, exists := c.Get("user")
+		user, ok := userVal.(*model.User)
+		if !exists || !ok || !allowed[user.Role] {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"code":    ErrCodeInsufficientRole,
+				"message": "Your role does not allow this action",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// abortUnauthorized aborts the request with a 401 and an error code
+func abortUnauthorized(c *gin.Context, code, message string) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":   "Unauthorized",
+		"code":    code,
+		"message": message,
+	})
+	c.Abort()
+}
+
+// abortInternalError aborts the request with a 500
+func abortInternalError(c *gin.Context, err error) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Internal server error",
+		"message": err.Error(),
+	})
+	c.Abort()
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit log actions
+const (
+	AuditActionLogin             = "login"
+	AuditActionLoginFailed       = "login_failed"
+	AuditActionPasswordChange    = "password_change"
+	AuditActionLogout            = "logout"
+	AuditActionLogoutAll         = "logout_all"
+	AuditActionSessionRevoke     = "session_revoke"
+	AuditActionRefreshTokenReuse = "refresh_token_reuse"
+	AuditActionDiaryCreate       = "diary_create"
+	AuditActionDiaryUpdate       = "diary_update"
+	AuditActionDiaryDelete       = "diary_delete"
+	AuditActionDiaryCopy         = "diary_copy"
+	AuditActionAccountLocked     = "account_locked"
+	AuditActionAccountUnlock     = "account_unlock"
+	AuditActionAPIKeyCreate      = "api_key_create"
+	AuditActionAPIKeyRevoke      = "api_key_revoke"
+	AuditActionRoleChange        = "role_change"
+	AuditActionMFAEnable         = "mfa_enable"
+	AuditActionMFADisable        = "mfa_disable"
+	AuditActionIdentityLink      = "identity_link"
+	AuditActionIdentityUnlink    = "identity_unlink"
+	AuditActionGoalSet           = "goal_set"
+	AuditActionGoalDelete        = "goal_delete"
+)
+
+// Audit log resource types
+const (
+	AuditResourceUser          = "user"
+	AuditResourceSession       = "session"
+	AuditResourceFoodEntry     = "food_entry"
+	AuditResourceAPIKey        = "api_key"
+	AuditResourceIdentity      = "identity"
+	AuditResourceNutritionGoal = "nutrition_goal"
+)
+
+// AuditLog represents an entry in auth.audit_logs
+type AuditLog struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	UserID       *uuid.UUID      `json:"user_id,omitempty" db:"user_id"`
+	Action       string          `json:"action" db:"action"`
+	ResourceType *string         `json:"resource_type,omitempty" db:"resource_type"`
+	ResourceID   *uuid.UUID      `json:"resource_id,omitempty" db:"resource_id"`
+	IPAddress    *string         `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent    *string         `json:"user_agent,omitempty" db:"user_agent"`
+	Metadata     json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditLogQuery represents filters for querying audit logs
+type AuditLogQuery struct {
+	UserID string `form:"user_id" binding:"omitempty,uuid"`
+	Action string `form:"action"`
+	From   string `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To     string `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit  int    `form:"limit,default=50"`
+	Offset int    `form:"offset,default=0"`
+}
+
+// AuditLogFilter represents parsed audit log filters
+type AuditLogFilter struct {
+	UserID *uuid.UUID
+	Action string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+// AuditLogResponse represents a page of audit logs
+type AuditLogResponse struct {
+	Data       []*AuditLog `json:"data"`
+	Pagination *Pagination `json:"pagination"`
+}
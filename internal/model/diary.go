@@ -20,6 +20,52 @@ type FoodEntry struct {
 	CalculatedFat      *float64   `json:"calculated_fat,omitempty" db:"calculated_fat"`
 	CalculatedCarbs    *float64   `json:"calculated_carbs,omitempty" db:"calculated_carbs"`
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	// Snapshot of the USDA food taken when the entry was logged
+	FoodDescription  *string          `json:"food_description,omitempty" db:"food_description"`
+	NutrientsPer100g []*EntryNutrient `json:"nutrients_per_100g,omitempty" db:"nutrients_per_100g"`
+	// NutrientsOverridden is set when the user replaced the USDA nutrients with custom values
+	NutrientsOverridden bool `json:"nutrients_overridden" db:"nutrients_overridden"`
+}
+
+// EntryNutrient is a per-100 g nutrient amount captured when an entry was logged
+type EntryNutrient struct {
+	NutrientID int     `json:"nutrient_id"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	Amount     float64 `json:"amount"`
+}
+
+// NewEntryNutrients captures the nutrients of a USDA food for a diary entry
+func NewEntryNutrients(nutrients []*FoodNutrient) []*EntryNutrient {
+	snapshot := make([]*EntryNutrient, 0, len(nutrients))
+	for _, nutrient := range nutrients {
+		snapshot = append(snapshot, &EntryNutrient{
+			NutrientID: nutrient.NutrientID,
+			Name:       nutrient.NutrientName,
+			Unit:       nutrient.UnitName,
+			Amount:     nutrient.Amount,
+		})
+	}
+	return snapshot
+}
+
+// SnapshotProfile builds the per-100 g profile from the entry snapshot, or returns
+// nil for entries logged without one
+func (e *FoodEntry) SnapshotProfile() *NutrientProfile {
+	if e.NutrientsPer100g == nil {
+		return nil
+	}
+
+	nutrients := make([]*FoodNutrient, 0, len(e.NutrientsPer100g))
+	for _, nutrient := range e.NutrientsPer100g {
+		nutrients = append(nutrients, &FoodNutrient{
+			NutrientID:   nutrient.NutrientID,
+			NutrientName: nutrient.Name,
+			UnitName:     nutrient.Unit,
+			Amount:       nutrient.Amount,
+		})
+	}
+	return NewNutrientProfile(nutrients)
 }
 
 // FoodEntryCreate represents data needed to create a new food entry
@@ -33,6 +79,9 @@ type FoodEntryCreate struct {
 	CustomProtein   *float64 `json:"custom_protein,omitempty"`
 	CustomFat       *float64 `json:"custom_fat,omitempty"`
 	CustomCarbs     *float64 `json:"custom_carbs,omitempty"`
+	// OverrideNutrients makes custom_* values replace the nutrients calculated from
+	// the USDA food; without it they are ignored for entries with fdc_id
+	OverrideNutrients bool `json:"override_nutrients,omitempty"`
 }
 
 // FoodEntryUpdate represents data needed to update a food entry
@@ -59,12 +108,52 @@ type DaySummary struct {
 	TotalCarbs    float64 `json:"total_carbs"`
 	MealCount     int     `json:"meal_count"`
 	FoodCount     int     `json:"food_count"`
+	// Nutrients holds totals by USDA nutrient when requested with the nutrients parameter
+	Nutrients []*NutrientTotal `json:"nutrients,omitempty"`
+	// Goal compares consumption with the goals active on the summarized days
+	Goal *GoalProgress `json:"goal,omitempty"`
+}
+
+// NutrientTotal is the amount of a USDA nutrient consumed over a day or period.
+// Energy, protein, fat and carbs are the calculated entry values and match the
+// summary totals. Other nutrients come from USDA food snapshots only, so custom
+// foods add nothing to them; EntryCount tells how many entries contributed.
+type NutrientTotal struct {
+	NutrientID int     `json:"nutrient_id"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	Amount     float64 `json:"amount"`
+	EntryCount int     `json:"entry_count"`
+}
+
+// FilterNutrientTotals keeps the totals selected by fields
+func FilterNutrientTotals(totals []*NutrientTotal, fields NutrientFields) []*NutrientTotal {
+	switch fields {
+	case NutrientFieldsAll:
+		return totals
+	case NutrientFieldsCore, NutrientFieldsExtended:
+		ids := fields.NutrientIDs()
+		filtered := make([]*NutrientTotal, 0, len(ids))
+		for _, total := range totals {
+			for _, id := range ids {
+				if total.NutrientID == id {
+					filtered = append(filtered, total)
+					break
+				}
+			}
+		}
+		return filtered
+	default:
+		return nil
+	}
 }
 
 // DiaryPeriodRequest represents request parameters for getting diary entries
 type DiaryPeriodRequest struct {
 	Date      string `form:"date" binding:"required,datetime=2006-01-02"`
-	DaysCount int    `form:"daysCount,default=1"`
+	DaysCount int    `form:"daysCount,default=1" binding:"min=1,max=366"`
+	// Nutrients selects the nutrient totals in day summaries: none (default), core, extended or all
+	Nutrients NutrientFields `form:"nutrients" binding:"omitempty,oneof=none core extended all"`
 }
 
 // DiaryPeriodResponse represents response with diary entries for a period
@@ -79,7 +168,9 @@ type DiaryPeriodResponse struct {
 // DiarySummaryRequest represents request parameters for getting diary summary
 type DiarySummaryRequest struct {
 	Date      string `form:"date" binding:"required,datetime=2006-01-02"`
-	DaysCount int    `form:"daysCount,default=1"`
+	DaysCount int    `form:"daysCount,default=1" binding:"min=1,max=366"`
+	// Nutrients selects the nutrient totals in the summary: none (default), core, extended or all
+	Nutrients NutrientFields `form:"nutrients" binding:"omitempty,oneof=none core extended all"`
 }
 
 // DiarySummaryResponse represents nutritional summary for a period
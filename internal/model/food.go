@@ -34,6 +34,8 @@ type FoodNutrient struct {
 type FoodWithNutrients struct {
 	Food     *Food           `json:"food"`
 	Nutrients []*FoodNutrient `json:"nutrients"`
+	// Profile holds energy and key nutrients per 100 g
+	Profile *NutrientProfile `json:"profile"`
 }
 
 // SearchFoodRequest represents the request parameters for searching foods
@@ -41,6 +43,8 @@ type SearchFoodRequest struct {
 	Query  string `form:"q" binding:"required"`
 	Limit  int    `form:"limit,default=20"`
 	Offset int    `form:"offset,default=0"`
+	// Fields selects the nutrients returned per food: none, core, extended or all (default)
+	Fields NutrientFields `form:"fields" binding:"omitempty,oneof=none core extended all"`
 }
 
 // SearchFoodResponse represents the response for food search
@@ -0,0 +1,173 @@
+package model
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Energy per gram used to convert macro targets given in percent of energy
+const (
+	KcalPerGramProtein = 4
+	KcalPerGramFat     = 9
+	KcalPerGramCarbs   = 4
+)
+
+// NutritionGoal holds the daily targets of a user from EffectiveFrom until the next goal
+type NutritionGoal struct {
+	ID             uuid.UUID         `json:"id" db:"id"`
+	UserID         uuid.UUID         `json:"user_id" db:"user_id"`
+	EffectiveFrom  time.Time         `json:"effective_from" db:"effective_from"`
+	Calories       *float64          `json:"calories,omitempty" db:"calories"`
+	Protein        *MacroTarget      `json:"protein,omitempty"`
+	Fat            *MacroTarget      `json:"fat,omitempty"`
+	Carbs          *MacroTarget      `json:"carbs,omitempty"`
+	Micronutrients []*NutrientTarget `json:"micronutrients,omitempty" db:"micronutrients"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// MacroTarget is a daily macronutrient target, either in grams or in percent of
+// the calorie target
+type MacroTarget struct {
+	Grams   *float64 `json:"grams,omitempty" binding:"omitempty,gt=0"`
+	Percent *float64 `json:"percent,omitempty" binding:"omitempty,gt=0,lte=100"`
+}
+
+// NutrientTarget is a daily target for a USDA nutrient, in the nutrient's USDA unit
+type NutrientTarget struct {
+	NutrientID int     `json:"nutrient_id" binding:"required,gt=0"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// NutritionGoalUpdate represents a request to set the goal starting at a date
+type NutritionGoalUpdate struct {
+	Calories       *float64          `json:"calories,omitempty" binding:"omitempty,gt=0"`
+	Protein        *MacroTarget      `json:"protein,omitempty"`
+	Fat            *MacroTarget      `json:"fat,omitempty"`
+	Carbs          *MacroTarget      `json:"carbs,omitempty"`
+	Micronutrients []*NutrientTarget `json:"micronutrients,omitempty" binding:"omitempty,dive,required"`
+}
+
+// ActiveGoal returns the goal in effect on date from goals sorted by EffectiveFrom,
+// or nil when the user had no goal yet
+func ActiveGoal(goals []*NutritionGoal, date time.Time) *NutritionGoal {
+	var active *NutritionGoal
+	for _, goal := range goals {
+		if goal.EffectiveFrom.After(date) {
+			break
+		}
+		active = goal
+	}
+	return active
+}
+
+// macroGrams resolves a macro target to grams using the calorie target
+func (g *NutritionGoal) macroGrams(target *MacroTarget, kcalPerGram float64) *float64 {
+	if target == nil {
+		return nil
+	}
+	if target.Grams != nil {
+		return target.Grams
+	}
+	if target.Percent != nil && g.Calories != nil {
+		grams := *g.Calories * *target.Percent / 100 / kcalPerGram
+		return &grams
+	}
+	return nil
+}
+
+// GoalProgress compares consumption with the goals of one or more days. Targets
+// and consumption only cover days that had an active goal.
+type GoalProgress struct {
+	DaysWithGoal   int                 `json:"days_with_goal"`
+	Calories       *TargetProgress     `json:"calories,omitempty"`
+	Protein        *TargetProgress     `json:"protein,omitempty"`
+	Fat            *TargetProgress     `json:"fat,omitempty"`
+	Carbs          *TargetProgress     `json:"carbs,omitempty"`
+	Micronutrients []*NutrientProgress `json:"micronutrients,omitempty"`
+}
+
+// TargetProgress is the progress towards one target. Remaining is negative when
+// the target was exceeded.
+type TargetProgress struct {
+	Target    float64 `json:"target"`
+	Consumed  float64 `json:"consumed"`
+	Remaining float64 `json:"remaining"`
+	Percent   float64 `json:"percent"`
+}
+
+// NutrientProgress is the progress towards a micronutrient target
+type NutrientProgress struct {
+	NutrientID int    `json:"nutrient_id"`
+	Name       string `json:"name,omitempty"`
+	Unit       string `json:"unit,omitempty"`
+	TargetProgress
+}
+
+// Add adds one day judged against the goal active on it. The summary must include
+// nutrient totals for the goal's micronutrients.
+func (p *GoalProgress) Add(goal *NutritionGoal, summary *DaySummary) {
+	p.DaysWithGoal++
+	p.Calories = addTarget(p.Calories, goal.Calories, summary.TotalCalories)
+	p.Protein = addTarget(p.Protein, goal.macroGrams(goal.Protein, KcalPerGramProtein), summary.TotalProtein)
+	p.Fat = addTarget(p.Fat, goal.macroGrams(goal.Fat, KcalPerGramFat), summary.TotalFat)
+	p.Carbs = addTarget(p.Carbs, goal.macroGrams(goal.Carbs, KcalPerGramCarbs), summary.TotalCarbs)
+
+	for _, target := range goal.Micronutrients {
+		var consumed *NutrientTotal
+		for _, total := range summary.Nutrients {
+			if total.NutrientID == target.NutrientID {
+				consumed = total
+				break
+			}
+		}
+
+		var progress *NutrientProgress
+		for _, existing := range p.Micronutrients {
+			if existing.NutrientID == target.NutrientID {
+				progress = existing
+				break
+			}
+		}
+		if progress == nil {
+			progress = &NutrientProgress{NutrientID: target.NutrientID}
+			p.Micronutrients = append(p.Micronutrients, progress)
+		}
+
+		amount := 0.0
+		if consumed != nil {
+			amount = consumed.Amount
+			progress.Name = consumed.Name
+			progress.Unit = consumed.Unit
+		}
+		progress.add(target.Amount, amount)
+	}
+}
+
+// addTarget adds a day to the progress towards an optional target
+func addTarget(progress *TargetProgress, target *float64, consumed float64) *TargetProgress {
+	if target == nil {
+		return progress
+	}
+	if progress == nil {
+		progress = &TargetProgress{}
+	}
+	progress.add(*target, consumed)
+	return progress
+}
+
+// add adds a day's target and consumption and updates remaining and percent
+func (t *TargetProgress) add(target, consumed float64) {
+	t.Target = roundTo(t.Target+target, 2)
+	t.Consumed = roundTo(t.Consumed+consumed, 2)
+	t.Remaining = roundTo(t.Target-t.Consumed, 2)
+	t.Percent = roundTo(t.Consumed/t.Target*100, 1)
+}
+
+// roundTo rounds value to the given number of decimals
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an external OpenID Connect account (issuer + subject) to a user
+type Identity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"-" db:"user_id"`
+	Provider    string     `json:"provider" db:"provider"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       *string    `json:"email,omitempty" db:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OIDCState is a pending authorization request awaiting the provider callback.
+// BindingHash ties it to the browser that started it. UserID and SessionID are set
+// when an authenticated user is linking a provider.
+type OIDCState struct {
+	ID           uuid.UUID  `db:"id"`
+	StateHash    string     `db:"state_hash"`
+	BindingHash  string     `db:"binding_hash"`
+	Provider     string     `db:"provider"`
+	CodeVerifier string     `db:"code_verifier"`
+	Nonce        string     `db:"nonce"`
+	UserID       *uuid.UUID `db:"user_id"`
+	SessionID    *uuid.UUID `db:"session_id"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+// OIDCAuthorizationResponse returns the provider URL to send the browser to
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
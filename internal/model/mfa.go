@@ -0,0 +1,31 @@
+package model
+
+// MFAChallenge is returned by login instead of a TokenPair when the account has 2FA enabled
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"` // seconds
+}
+
+// MFALoginRequest completes a login with a TOTP or recovery code
+type MFALoginRequest struct {
+	MFAToken   string  `json:"mfa_token" binding:"required"`
+	Code       string  `json:"code" binding:"required,max=32"`
+	DeviceInfo *string `json:"device_info,omitempty"`
+}
+
+// TOTPEnrollment is returned when a user starts TOTP enrollment
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest carries a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// RecoveryCodesResponse returns freshly generated recovery codes; they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
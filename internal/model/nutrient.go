@@ -0,0 +1,234 @@
+package model
+
+import "math"
+
+// USDA FoodData Central nutrient IDs
+const (
+	NutrientProtein               = 1003
+	NutrientFat                   = 1004
+	NutrientCarbohydrate          = 1005
+	NutrientEnergy                = 1008
+	NutrientCarbohydrateSummation = 1050
+	NutrientSugarsNLEA            = 1063
+	NutrientFiber                 = 1079
+	NutrientFatNLEA               = 1085
+	NutrientCalcium               = 1087
+	NutrientIron                  = 1089
+	NutrientMagnesium             = 1090
+	NutrientPotassium             = 1092
+	NutrientSodium                = 1093
+	NutrientZinc                  = 1095
+	NutrientVitaminA              = 1106
+	NutrientVitaminE              = 1109
+	NutrientVitaminD              = 1114
+	NutrientVitaminC              = 1162
+	NutrientVitaminB12            = 1178
+	NutrientVitaminK              = 1185
+	NutrientFolate                = 1190
+	NutrientCholesterol           = 1253
+	NutrientTransFat              = 1257
+	NutrientSaturatedFat          = 1258
+	NutrientMonounsaturatedFat    = 1292
+	NutrientPolyunsaturatedFat    = 1293
+	NutrientSugars                = 2000
+	NutrientEnergyAtwaterGeneral  = 2047
+	NutrientEnergyAtwaterSpecific = 2048
+)
+
+// NutrientFields selects which nutrients are returned with a food
+type NutrientFields string
+
+const (
+	// NutrientFieldsNone returns foods without nutrients
+	NutrientFieldsNone NutrientFields = "none"
+	// NutrientFieldsCore returns energy and macronutrients only
+	NutrientFieldsCore NutrientFields = "core"
+	// NutrientFieldsExtended returns the profile nutrients plus common minerals,
+	// vitamins and fats
+	NutrientFieldsExtended NutrientFields = "extended"
+	// NutrientFieldsAll returns every nutrient of the food
+	NutrientFieldsAll NutrientFields = "all"
+)
+
+// CoreNutrientIDs are the nutrients returned for NutrientFieldsCore
+var CoreNutrientIDs = []int{
+	NutrientProtein,
+	NutrientFat,
+	NutrientCarbohydrate,
+	NutrientEnergy,
+	NutrientEnergyAtwaterGeneral,
+	NutrientEnergyAtwaterSpecific,
+}
+
+// NutrientIDs returns the nutrients selected by f, or nil when f selects all or none
+func (f NutrientFields) NutrientIDs() []int {
+	switch f {
+	case NutrientFieldsCore:
+		return CoreNutrientIDs
+	case NutrientFieldsExtended:
+		return ExtendedNutrientIDs
+	default:
+		return nil
+	}
+}
+
+// Candidate USDA nutrients for each profile value, in order of preference
+var (
+	energySources       = []int{NutrientEnergy, NutrientEnergyAtwaterSpecific, NutrientEnergyAtwaterGeneral}
+	proteinSources      = []int{NutrientProtein}
+	fatSources          = []int{NutrientFat, NutrientFatNLEA}
+	carbohydrateSources = []int{NutrientCarbohydrate, NutrientCarbohydrateSummation}
+	fiberSources        = []int{NutrientFiber}
+	sugarsSources       = []int{NutrientSugars, NutrientSugarsNLEA}
+	sodiumSources       = []int{NutrientSodium}
+	saturatedFatSources = []int{NutrientSaturatedFat}
+)
+
+// profileSources holds the candidates of every profile value
+var profileSources = [][]int{
+	energySources,
+	proteinSources,
+	fatSources,
+	carbohydrateSources,
+	fiberSources,
+	sugarsSources,
+	sodiumSources,
+	saturatedFatSources,
+}
+
+// ProfileNutrientIDs are all nutrients NewNutrientProfile may read
+var ProfileNutrientIDs = concatNutrientIDs(profileSources...)
+
+// MacroNutrientIDs are the nutrients behind the calories, protein, fat and carbs
+// calculated for diary entries
+var MacroNutrientIDs = concatNutrientIDs(
+	energySources,
+	proteinSources,
+	fatSources,
+	carbohydrateSources,
+)
+
+// NutrientAlternates lists every profile nutrient together with the preferred
+// nutrient of its profile value and its rank in order of preference
+func NutrientAlternates() (ids, preferred, ranks []int) {
+	for _, sources := range profileSources {
+		for rank, id := range sources {
+			ids = append(ids, id)
+			preferred = append(preferred, sources[0])
+			ranks = append(ranks, rank)
+		}
+	}
+	return ids, preferred, ranks
+}
+
+// ExtendedNutrientIDs are the nutrients returned for NutrientFieldsExtended
+var ExtendedNutrientIDs = concatNutrientIDs(ProfileNutrientIDs, []int{
+	NutrientCalcium,
+	NutrientIron,
+	NutrientMagnesium,
+	NutrientPotassium,
+	NutrientZinc,
+	NutrientVitaminA,
+	NutrientVitaminE,
+	NutrientVitaminD,
+	NutrientVitaminC,
+	NutrientVitaminB12,
+	NutrientVitaminK,
+	NutrientFolate,
+	NutrientCholesterol,
+	NutrientTransFat,
+	NutrientMonounsaturatedFat,
+	NutrientPolyunsaturatedFat,
+})
+
+// NutrientValue is an amount together with the USDA nutrient it was taken from
+type NutrientValue struct {
+	Amount     float64 `json:"amount"`
+	Unit       string  `json:"unit"`
+	NutrientID int     `json:"nutrient_id"`
+}
+
+// NutrientProfile holds energy and key nutrients per 100 g of a food. A nil value
+// means the food has no data for that nutrient.
+type NutrientProfile struct {
+	Energy       *NutrientValue `json:"energy_kcal"`
+	Protein      *NutrientValue `json:"protein"`
+	Fat          *NutrientValue `json:"fat"`
+	Carbohydrate *NutrientValue `json:"carbohydrate"`
+	Fiber        *NutrientValue `json:"fiber"`
+	Sugars       *NutrientValue `json:"sugars"`
+	Sodium       *NutrientValue `json:"sodium"`
+	SaturatedFat *NutrientValue `json:"saturated_fat"`
+}
+
+// NewNutrientProfile builds the per-100 g profile from USDA nutrient rows. Energy
+// comes from nutrient 1008, falling back to the Atwater specific (2048) and then
+// general (2047) factors.
+func NewNutrientProfile(nutrients []*FoodNutrient) *NutrientProfile {
+	byID := make(map[int]*FoodNutrient, len(nutrients))
+	for _, nutrient := range nutrients {
+		byID[nutrient.NutrientID] = nutrient
+	}
+
+	return &NutrientProfile{
+		Energy:       pickNutrient(byID, energySources),
+		Protein:      pickNutrient(byID, proteinSources),
+		Fat:          pickNutrient(byID, fatSources),
+		Carbohydrate: pickNutrient(byID, carbohydrateSources),
+		Fiber:        pickNutrient(byID, fiberSources),
+		Sugars:       pickNutrient(byID, sugarsSources),
+		Sodium:       pickNutrient(byID, sodiumSources),
+		SaturatedFat: pickNutrient(byID, saturatedFatSources),
+	}
+}
+
+// Macros holds energy (kcal) and macronutrients (g) for an amount of food. A nil
+// value means the food has no data for that nutrient.
+type Macros struct {
+	Calories *float64
+	Protein  *float64
+	Fat      *float64
+	Carbs    *float64
+}
+
+// MacrosFor scales the per-100 g profile to the given amount of food
+func (p *NutrientProfile) MacrosFor(amountGrams float64) Macros {
+	return Macros{
+		Calories: scaleNutrient(p.Energy, amountGrams),
+		Protein:  scaleNutrient(p.Protein, amountGrams),
+		Fat:      scaleNutrient(p.Fat, amountGrams),
+		Carbs:    scaleNutrient(p.Carbohydrate, amountGrams),
+	}
+}
+
+// scaleNutrient converts a per-100 g value to amountGrams, rounded to 0.01
+func scaleNutrient(value *NutrientValue, amountGrams float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	scaled := math.Round(value.Amount*amountGrams) / 100
+	return &scaled
+}
+
+// pickNutrient returns the first available nutrient among the candidates
+func pickNutrient(byID map[int]*FoodNutrient, candidates []int) *NutrientValue {
+	for _, id := range candidates {
+		if nutrient, ok := byID[id]; ok {
+			return &NutrientValue{
+				Amount:     nutrient.Amount,
+				Unit:       nutrient.UnitName,
+				NutrientID: nutrient.NutrientID,
+			}
+		}
+	}
+	return nil
+}
+
+// concatNutrientIDs joins nutrient ID lists
+func concatNutrientIDs(lists ...[]int) []int {
+	var ids []int
+	for _, list := range lists {
+		ids = append(ids, list...)
+	}
+	return ids
+}
@@ -13,6 +13,7 @@ const (
 	TokenTypeAccess  TokenType = "access"
 	TokenTypeRefresh TokenType = "refresh"
 	TokenTypeReset   TokenType = "reset"
+	TokenTypeMFA     TokenType = "mfa" // short-lived challenge between password and second factor
 )
 
 // TokenPair represents a pair of access and refresh tokens
@@ -30,11 +31,18 @@ type RefreshToken struct {
 	TokenHash  string     `db:"token_hash"`
 	DeviceInfo *string    `db:"device_info"`
 	IPAddress  *string    `db:"ip_address"`
+	FamilyID   uuid.UUID  `db:"family_id"`
+	ReplacedBy *uuid.UUID `db:"replaced_by"`
 	ExpiresAt  time.Time  `db:"expires_at"`
 	RevokedAt  *time.Time `db:"revoked_at"`
 	CreatedAt  time.Time  `db:"created_at"`
 }
 
+// RefreshTokenRequest represents a request to exchange a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 // PasswordResetToken represents a password reset token
 type PasswordResetToken struct {
 	ID        uuid.UUID  `db:"id"`
@@ -45,6 +53,16 @@ type PasswordResetToken struct {
 	CreatedAt time.Time  `db:"created_at"`
 }
 
+// EmailVerificationToken represents an email verification token
+type EmailVerificationToken struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
 // RevokedAccessToken represents a revoked access token
 type RevokedAccessToken struct {
 	ID        uuid.UUID  `db:"id"`
@@ -63,15 +81,48 @@ type TokenClaims struct {
 	Exp     int64     `json:"exp"`
 	Iat     int64     `json:"iat"`
 	Type    TokenType `json:"type"`
+	Role    Role      `json:"role,omitempty"`
+	// SessionID is the refresh token family the access token was issued for
+	SessionID string `json:"sid,omitempty"`
 }
 
+// Session represents an active login on a device (a refresh token family)
+type Session struct {
+	ID          uuid.UUID `json:"id"`
+	DeviceInfo  *string   `json:"device_info,omitempty"`
+	IPAddress   *string   `json:"ip_address,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Current     bool      `json:"current"`
+}
+
+// TokenPurgeResult holds the number of expired rows removed per table
+type TokenPurgeResult struct {
+	RefreshTokens           int64
+	PasswordResetTokens     int64
+	EmailVerificationTokens int64
+	RevokedAccessTokens     int64
+}
+
+// Revocation reasons stored with revoked access tokens
+const (
+	RevokeReasonLogout    = "logout"
+	RevokeReasonLogoutAll = "logout_all"
+)
+
 // PasswordResetRequest represents a password reset request
 type PasswordResetRequest struct {
-	Email string `json:"email" validate:"required,email"`
+	Email string `json:"email" binding:"required,email"`
 }
 
 // PasswordResetConfirm represents password reset confirmation
 type PasswordResetConfirm struct {
-	Token    string `json:"token" validate:"required"`
-	Password string `json:"password" validate:"required,min=8"`
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// EmailVerificationConfirm represents email verification confirmation
+type EmailVerificationConfirm struct {
+	Token string `json:"token" binding:"required"`
 }
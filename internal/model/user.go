@@ -6,40 +6,70 @@ import (
 	"github.com/google/uuid"
 )
 
+// Role represents the authorization role of a user
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleDietitian Role = "dietitian"
+	RoleAdmin     Role = "admin"
+)
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleDietitian, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 // User represents a user in the system
 type User struct {
-	ID           uuid.UUID  `json:"id" db:"id"`
-	Email        string     `json:"email" db:"email"`
-	PasswordHash string     `json:"-" db:"password_hash"`
-	FirstName    *string    `json:"first_name,omitempty" db:"first_name"`
-	LastName     *string    `json:"last_name,omitempty" db:"last_name"`
-	IsActive     bool       `json:"is_active" db:"is_active"`
-	IsVerified   bool       `json:"is_verified" db:"is_verified"`
-	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	Email               string     `json:"email" db:"email"`
+	PasswordHash        string     `json:"-" db:"password_hash"`
+	FirstName           *string    `json:"first_name,omitempty" db:"first_name"`
+	LastName            *string    `json:"last_name,omitempty" db:"last_name"`
+	IsActive            bool       `json:"is_active" db:"is_active"`
+	IsVerified          bool       `json:"is_verified" db:"is_verified"`
+	Role                Role       `json:"role" db:"role"`
+	TOTPSecret          *string    `json:"-" db:"totp_secret"`
+	TOTPEnabled         bool       `json:"totp_enabled" db:"totp_enabled"`
+	LastLoginAt         *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	FailedLoginAttempts int        `json:"-" db:"failed_login_attempts"`
+	LockoutCount        int        `json:"-" db:"lockout_count"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 // UserCreate represents data needed to create a new user
 type UserCreate struct {
-	Email     string  `json:"email" validate:"required,email"`
-	Password  string  `json:"password" validate:"required,min=8"`
-	FirstName *string `json:"first_name,omitempty" validate:"omitempty,max=100"`
-	LastName  *string `json:"last_name,omitempty" validate:"omitempty,max=100"`
+	Email     string  `json:"email" binding:"required,email,max=255"`
+	Password  string  `json:"password" binding:"required,max=72"`
+	FirstName *string `json:"first_name,omitempty" binding:"omitempty,max=100"`
+	LastName  *string `json:"last_name,omitempty" binding:"omitempty,max=100"`
+}
+
+// UserRoleUpdate represents a request to change the role of a user
+type UserRoleUpdate struct {
+	Role Role `json:"role" binding:"required,oneof=user dietitian admin"`
 }
 
 // UserUpdate represents data needed to update a user
 type UserUpdate struct {
-	FirstName *string `json:"first_name,omitempty" validate:"omitempty,max=100"`
-	LastName  *string `json:"last_name,omitempty" validate:"omitempty,max=100"`
+	FirstName *string `json:"first_name,omitempty" binding:"omitempty,max=100"`
+	LastName  *string `json:"last_name,omitempty" binding:"omitempty,max=100"`
 	IsActive  *bool   `json:"is_active,omitempty"`
 }
 
 // UserLogin represents login credentials
 type UserLogin struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string  `json:"email" binding:"required,email"`
+	Password   string  `json:"password" binding:"required"`
+	DeviceInfo *string `json:"device_info,omitempty" binding:"omitempty,max=255"`
 }
 
 // UserResponse represents user data returned in API responses
@@ -50,10 +80,23 @@ type UserResponse struct {
 	LastName    *string    `json:"last_name,omitempty"`
 	IsActive    bool       `json:"is_active"`
 	IsVerified  bool       `json:"is_verified"`
+	Role        Role       `json:"role"`
+	TOTPEnabled bool       `json:"totp_enabled"`
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
 
+// HasPassword reports whether the user can log in with a password. Accounts created
+// through an external identity provider have none until they reset it.
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// IsLocked reports whether the account is locked at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(now)
+}
+
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
@@ -63,6 +106,8 @@ func (u *User) ToResponse() UserResponse {
 		LastName:    u.LastName,
 		IsActive:    u.IsActive,
 		IsVerified:  u.IsVerified,
+		Role:        u.Role,
+		TOTPEnabled: u.TOTPEnabled,
 		LastLoginAt: u.LastLoginAt,
 		CreatedAt:   u.CreatedAt,
 	}
@@ -0,0 +1,173 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/yourusername/auth-service/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// Errors returned by the registry
+var (
+	ErrUnknownProvider = errors.New("unknown OIDC provider")
+	ErrNonceMismatch   = errors.New("ID token nonce does not match")
+)
+
+// Identity is the verified identity returned by a provider
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// AuthRequest holds the per-login secrets that must be kept until the callback
+type AuthRequest struct {
+	URL          string
+	CodeVerifier string
+	Nonce        string
+}
+
+// provider lazily discovers an issuer on first use so the service can start
+// before the identity provider is reachable
+type provider struct {
+	cfg config.OIDCProviderConfig
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// Registry holds the configured OIDC providers keyed by name
+type Registry struct {
+	providers map[string]*provider
+}
+
+// NewRegistry creates a registry for the configured providers
+func NewRegistry(cfg config.OIDCConfig) *Registry {
+	providers := make(map[string]*provider, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		providers[p.Name] = &provider{cfg: p}
+	}
+	return &Registry{providers: providers}
+}
+
+// Has reports whether a provider with the given name is configured
+func (r *Registry) Has(name string) bool {
+	_, ok := r.providers[name]
+	return ok
+}
+
+// Issuer returns the configured issuer URL of a provider
+func (r *Registry) Issuer(name string) (string, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	return p.cfg.IssuerURL, nil
+}
+
+// AuthCodeURL builds an authorization URL with PKCE (S256) for the provider
+func (r *Registry) AuthCodeURL(ctx context.Context, name, state string) (*AuthRequest, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	oauth2Config, _, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := oauth2.GenerateVerifier()
+	nonce := oauth2.GenerateVerifier()
+
+	url := oauth2Config.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		gooidc.Nonce(nonce),
+	)
+
+	return &AuthRequest{URL: url, CodeVerifier: verifier, Nonce: nonce}, nil
+}
+
+// Exchange redeems an authorization code and verifies the returned ID token
+func (r *Registry) Exchange(ctx context.Context, name, code, codeVerifier, nonce string) (*Identity, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	oauth2Config, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("token response does not contain an id_token")
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse ID token claims: %w", err)
+	}
+
+	return &Identity{
+		Issuer:        idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+	}, nil
+}
+
+// discover fetches the provider metadata once and caches the OAuth2 config and verifier
+func (p *provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2 != nil {
+		return p.oauth2, p.verifier, nil
+	}
+
+	discovered, err := gooidc.NewProvider(ctx, p.cfg.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover OIDC provider %s: %w", p.cfg.Name, err)
+	}
+
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"email", "profile"}
+	}
+
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     discovered.Endpoint(),
+		Scopes:       append([]string{gooidc.ScopeOpenID}, scopes...),
+	}
+	p.verifier = discovered.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+
+	return p.oauth2, p.verifier, nil
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rule describes a token bucket: Burst tokens refilled at RequestsPerMinute
+type Rule struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, zero if allowed
+}
+
+// Store keeps rate limiter state. Implementations must be safe for concurrent use.
+type Store interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// bucket is the state of a single token bucket
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	capacity float64
+	rate     float64 // tokens per second
+}
+
+// MemoryStore is an in-process token bucket Store
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates a new in-memory Store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// sweepInterval controls how often idle buckets are dropped
+const sweepInterval = time.Minute
+
+// Allow takes one token from the bucket identified by key
+func (s *MemoryStore) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	capacity := float64(rule.Burst)
+	if capacity < 1 {
+		capacity = 1
+	}
+	ratePerSecond := float64(rule.RequestsPerMinute) / 60
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now, capacity: capacity}
+		s.buckets[key] = b
+	}
+
+	// Refill tokens for the elapsed time
+	elapsed := now.Sub(b.updated).Seconds()
+	b.tokens = math.Min(capacity, b.tokens+elapsed*ratePerSecond)
+	b.capacity = capacity
+	b.rate = ratePerSecond
+	b.updated = now
+
+	result := Result{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else if ratePerSecond > 0 {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / ratePerSecond)
+	} else {
+		result.RetryAfter = time.Minute
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	if ratePerSecond > 0 {
+		result.ResetAfter = secondsToDuration((capacity - b.tokens) / ratePerSecond)
+	}
+
+	return result, nil
+}
+
+// sweep removes buckets that have been idle long enough to be full again
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*b.rate >= b.capacity {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// secondsToDuration converts fractional seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/auth-service/internal/model"
+)
+
+// APIKeyRepository defines the interface for API key data access
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *model.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) (bool, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	Close() error
+}
+
+// apiKeyRepository implements APIKeyRepository with PostgreSQL
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// apiKeyColumns lists the columns selected for an API key
+const apiKeyColumns = `
+	id, user_id, name, key_prefix, key_hash, scopes,
+	last_used_at, expires_at, revoked_at, created_at
+`
+
+// lastUsedResolution limits how often last_used_at is written for a busy key
+const lastUsedResolution = time.Minute
+
+// scanAPIKey scans a single API key row
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	var key model.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.KeyPrefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.LastUsedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateAPIKey inserts a new API key
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	query := `
+		INSERT INTO auth.api_keys (
+			id, user_id, name, key_prefix, key_hash, scopes, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.KeyPrefix,
+		key.KeyHash,
+		pq.Array(key.Scopes),
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeyByHash retrieves an API key by its hash
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM auth.api_keys WHERE key_hash = $1"
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // API key not found
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns the non-revoked API keys of a user, newest first
+func (r *apiKeyRepository) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + `
+		FROM auth.api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*model.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key owned by the user, reporting whether an active key was found
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE auth.api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateAPIKeyLastUsed records when a key was last used. Writes are skipped when the
+// stored timestamp is more recent than lastUsedResolution.
+func (r *apiKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := `
+		UPDATE auth.api_keys
+		SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, usedAt, id, usedAt.Add(-lastUsedResolution))
+	if err != nil {
+		return fmt.Errorf("failed to update API key last used: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the database connection
+func (r *apiKeyRepository) Close() error {
+	return r.db.Close()
+}
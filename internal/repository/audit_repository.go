@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/auth-service/internal/model"
+)
+
+// AuditRepository defines the interface for audit log data access
+type AuditRepository interface {
+	CreateAuditLog(ctx context.Context, entry *model.AuditLog) error
+	ListAuditLogs(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, int, error)
+	Close() error
+}
+
+// auditRepository implements AuditRepository with PostgreSQL
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// CreateAuditLog inserts an audit log entry
+func (r *auditRepository) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	query := `
+		INSERT INTO auth.audit_logs (
+			id, user_id, action, resource_type, resource_id,
+			ip_address, user_agent, metadata, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	var metadata interface{}
+	if len(entry.Metadata) > 0 {
+		metadata = []byte(entry.Metadata)
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.ID,
+		entry.UserID,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.IPAddress,
+		entry.UserAgent,
+		metadata,
+		entry.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditLogs returns audit logs matching the filter, newest first, with the total count
+func (r *auditRepository) ListAuditLogs(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, int, error) {
+	// Build WHERE clause based on provided filters
+	var conditions []string
+	args := []interface{}{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// First, get total count for pagination
+	var total int
+	countQuery := "SELECT COUNT(*) FROM auth.audit_logs" + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	// Then, get paginated results
+	listQuery := fmt.Sprintf(`
+		SELECT
+			id, user_id, action, resource_type, resource_id,
+			host(ip_address), user_agent, metadata, created_at
+		FROM auth.audit_logs%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, listQuery, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []*model.AuditLog{}
+	for rows.Next() {
+		var entry model.AuditLog
+		var metadata []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Action,
+			&entry.ResourceType,
+			&entry.ResourceID,
+			&entry.IPAddress,
+			&entry.UserAgent,
+			&metadata,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		entry.Metadata = metadata
+		logs = append(logs, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating audit log rows: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// Close closes the database connection
+func (r *auditRepository) Close() error {
+	return r.db.Close()
+}
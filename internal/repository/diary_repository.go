@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/yourusername/auth-service/internal/model"
 )
 
@@ -21,8 +24,8 @@ type DiaryRepository interface {
 	DeleteFoodEntriesByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) error
 	
 	// Statistics
-	GetDaySummary(ctx context.Context, userID uuid.UUID, date time.Time) (*model.DaySummary, error)
-	GetPeriodSummary(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) (*model.DaySummary, error)
+	GetDailySummaries(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, nutrients model.NutrientFields) (map[string]*model.DaySummary, error)
+	GetPeriodSummary(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, nutrients model.NutrientFields) (*model.DaySummary, error)
 	
 	// Copy
 	CopyFoodEntries(ctx context.Context, userID uuid.UUID, sourceDate, targetDate time.Time) error
@@ -40,15 +43,67 @@ func NewDiaryRepository(db *sql.DB) DiaryRepository {
 	return &diaryRepository{db: db}
 }
 
+// foodEntryColumns lists the columns read by scanFoodEntry
+const foodEntryColumns = `
+	id, user_id, date, meal_type, fdc_id, custom_food_name,
+	amount_grams, calculated_calories, calculated_protein,
+	calculated_fat, calculated_carbs, food_description,
+	nutrients_per_100g, nutrients_overridden, created_at
+`
+
+// scanFoodEntry scans a single food entry row
+func scanFoodEntry(row rowScanner) (*model.FoodEntry, error) {
+	var entry model.FoodEntry
+	var nutrients []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.UserID,
+		&entry.Date,
+		&entry.MealType,
+		&entry.FDCID,
+		&entry.CustomFoodName,
+		&entry.AmountGrams,
+		&entry.CalculatedCalories,
+		&entry.CalculatedProtein,
+		&entry.CalculatedFat,
+		&entry.CalculatedCarbs,
+		&entry.FoodDescription,
+		&nutrients,
+		&entry.NutrientsOverridden,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nutrients != nil {
+		if err := json.Unmarshal(nutrients, &entry.NutrientsPer100g); err != nil {
+			return nil, fmt.Errorf("failed to decode nutrient snapshot: %w", err)
+		}
+	}
+
+	return &entry, nil
+}
+
 // CreateFoodEntry creates a new food entry in the diary
 func (r *diaryRepository) CreateFoodEntry(ctx context.Context, entry *model.FoodEntry) error {
 	query := `
 		INSERT INTO diary.food_entries (
 			id, user_id, date, meal_type, fdc_id, custom_food_name,
 			amount_grams, calculated_calories, calculated_protein,
-			calculated_fat, calculated_carbs, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			calculated_fat, calculated_carbs, food_description,
+			nutrients_per_100g, nutrients_overridden, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
+
+	var nutrients interface{}
+	if entry.NutrientsPer100g != nil {
+		encoded, err := json.Marshal(entry.NutrientsPer100g)
+		if err != nil {
+			return fmt.Errorf("failed to encode nutrient snapshot: %w", err)
+		}
+		nutrients = encoded
+	}
 	
 	_, err := r.db.ExecContext(ctx, query,
 		entry.ID,
@@ -62,6 +117,9 @@ func (r *diaryRepository) CreateFoodEntry(ctx context.Context, entry *model.Food
 		entry.CalculatedProtein,
 		entry.CalculatedFat,
 		entry.CalculatedCarbs,
+		entry.FoodDescription,
+		nutrients,
+		entry.NutrientsOverridden,
 		entry.CreatedAt,
 	)
 	
@@ -74,31 +132,9 @@ func (r *diaryRepository) CreateFoodEntry(ctx context.Context, entry *model.Food
 
 // GetFoodEntryByID retrieves a food entry by its ID
 func (r *diaryRepository) GetFoodEntryByID(ctx context.Context, id uuid.UUID) (*model.FoodEntry, error) {
-	query := `
-		SELECT 
-			id, user_id, date, meal_type, fdc_id, custom_food_name,
-			amount_grams, calculated_calories, calculated_protein,
-			calculated_fat, calculated_carbs, created_at
-		FROM diary.food_entries
-		WHERE id = $1
-	`
-	
-	var entry model.FoodEntry
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&entry.ID,
-		&entry.UserID,
-		&entry.Date,
-		&entry.MealType,
-		&entry.FDCID,
-		&entry.CustomFoodName,
-		&entry.AmountGrams,
-		&entry.CalculatedCalories,
-		&entry.CalculatedProtein,
-		&entry.CalculatedFat,
-		&entry.CalculatedCarbs,
-		&entry.CreatedAt,
-	)
+	query := "SELECT " + foodEntryColumns + " FROM diary.food_entries WHERE id = $1"
 	
+	entry, err := scanFoodEntry(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Entry not found
@@ -106,16 +142,14 @@ func (r *diaryRepository) GetFoodEntryByID(ctx context.Context, id uuid.UUID) (*
 		return nil, fmt.Errorf("failed to get food entry: %w", err)
 	}
 	
-	return &entry, nil
+	return entry, nil
 }
 
-// GetFoodEntriesByPeriod retrieves food entries for a user within a date period
+// GetFoodEntriesByPeriod retrieves food entries for a user within a date period.
+// Entries carry the food snapshot taken when they were logged.
 func (r *diaryRepository) GetFoodEntriesByPeriod(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*model.FoodEntry, error) {
 	query := `
-		SELECT 
-			id, user_id, date, meal_type, fdc_id, custom_food_name,
-			amount_grams, calculated_calories, calculated_protein,
-			calculated_fat, calculated_carbs, created_at
+		SELECT ` + foodEntryColumns + `
 		FROM diary.food_entries
 		WHERE user_id = $1 AND date >= $2 AND date <= $3
 		ORDER BY date DESC, 
@@ -138,25 +172,11 @@ func (r *diaryRepository) GetFoodEntriesByPeriod(ctx context.Context, userID uui
 	
 	var entries []*model.FoodEntry
 	for rows.Next() {
-		var entry model.FoodEntry
-		err := rows.Scan(
-			&entry.ID,
-			&entry.UserID,
-			&entry.Date,
-			&entry.MealType,
-			&entry.FDCID,
-			&entry.CustomFoodName,
-			&entry.AmountGrams,
-			&entry.CalculatedCalories,
-			&entry.CalculatedProtein,
-			&entry.CalculatedFat,
-			&entry.CalculatedCarbs,
-			&entry.CreatedAt,
-		)
+		entry, err := scanFoodEntry(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan food entry: %w", err)
 		}
-		entries = append(entries, &entry)
+		entries = append(entries, entry)
 	}
 	
 	if err := rows.Err(); err != nil {
@@ -166,60 +186,175 @@ func (r *diaryRepository) GetFoodEntriesByPeriod(ctx context.Context, userID uui
 	return entries, nil
 }
 
-// UpdateFoodEntry updates a food entry
+// UpdateFoodEntry updates a food entry. When the amount changes, nutrients of USDA
+// foods are recalculated from the food data and those of custom foods and overridden
+// entries are scaled proportionally; custom values provided in the update take
+// precedence and mark USDA entries as overridden.
 func (r *diaryRepository) UpdateFoodEntry(ctx context.Context, id uuid.UUID, update *model.FoodEntryUpdate) error {
-	// Build dynamic query based on provided fields
-	query := "UPDATE diary.food_entries SET "
-	args := []interface{}{}
-	argIndex := 1
-	
-	if update.AmountGrams != nil {
-		query += fmt.Sprintf("amount_grams = $%d, ", argIndex)
-		args = append(args, *update.AmountGrams)
-		argIndex++
+	if update.AmountGrams == nil && update.CustomCalories == nil && update.CustomProtein == nil &&
+		update.CustomFat == nil && update.CustomCarbs == nil {
+		return nil // Nothing to update
 	}
-	
+
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the entry so concurrent updates cannot mix amounts and nutrients
+	selectQuery := "SELECT " + foodEntryColumns + " FROM diary.food_entries WHERE id = $1 FOR UPDATE"
+
+	entry, err := scanFoodEntry(tx.QueryRowContext(ctx, selectQuery, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil // Entry not found
+		}
+		return fmt.Errorf("failed to get food entry: %w", err)
+	}
+
+	amountGrams := entry.AmountGrams
+	macros := model.Macros{
+		Calories: entry.CalculatedCalories,
+		Protein:  entry.CalculatedProtein,
+		Fat:      entry.CalculatedFat,
+		Carbs:    entry.CalculatedCarbs,
+	}
+
+	if update.AmountGrams != nil && *update.AmountGrams != entry.AmountGrams {
+		amountGrams = *update.AmountGrams
+		macros, err = recalculateMacros(ctx, tx, entry, amountGrams)
+		if err != nil {
+			return err
+		}
+	}
+
 	if update.CustomCalories != nil {
-		query += fmt.Sprintf("calculated_calories = $%d, ", argIndex)
-		args = append(args, *update.CustomCalories)
-		argIndex++
+		macros.Calories = update.CustomCalories
 	}
-	
 	if update.CustomProtein != nil {
-		query += fmt.Sprintf("calculated_protein = $%d, ", argIndex)
-		args = append(args, *update.CustomProtein)
-		argIndex++
+		macros.Protein = update.CustomProtein
 	}
-	
 	if update.CustomFat != nil {
-		query += fmt.Sprintf("calculated_fat = $%d, ", argIndex)
-		args = append(args, *update.CustomFat)
-		argIndex++
+		macros.Fat = update.CustomFat
 	}
-	
 	if update.CustomCarbs != nil {
-		query += fmt.Sprintf("calculated_carbs = $%d, ", argIndex)
-		args = append(args, *update.CustomCarbs)
-		argIndex++
+		macros.Carbs = update.CustomCarbs
 	}
-	
-	// Remove trailing comma and space
-	if len(args) == 0 {
-		return nil // Nothing to update
+
+	overridden := entry.NutrientsOverridden
+	if entry.FDCID != nil && (update.CustomCalories != nil || update.CustomProtein != nil ||
+		update.CustomFat != nil || update.CustomCarbs != nil) {
+		overridden = true
 	}
-	
-	query = query[:len(query)-2] // Remove ", "
-	query += fmt.Sprintf(" WHERE id = $%d", argIndex)
-	args = append(args, id)
-	
-	_, err := r.db.ExecContext(ctx, query, args...)
+
+	updateQuery := `
+		UPDATE diary.food_entries
+		SET amount_grams = $1, calculated_calories = $2, calculated_protein = $3,
+			calculated_fat = $4, calculated_carbs = $5, nutrients_overridden = $6
+		WHERE id = $7
+	`
+
+	_, err = tx.ExecContext(ctx, updateQuery,
+		amountGrams,
+		macros.Calories,
+		macros.Protein,
+		macros.Fat,
+		macros.Carbs,
+		overridden,
+		id,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to update food entry: %w", err)
 	}
-	
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
+// recalculateMacros returns the nutrients of an entry for a new amount. USDA foods
+// are recalculated from the entry snapshot, or from the food data for entries
+// logged without one; custom foods, entries with user-overridden nutrients and
+// USDA foods no longer in the database are scaled proportionally to the old amount.
+func recalculateMacros(ctx context.Context, tx *sql.Tx, entry *model.FoodEntry, amountGrams float64) (model.Macros, error) {
+	if !entry.NutrientsOverridden {
+		if profile := entry.SnapshotProfile(); profile != nil {
+			return profile.MacrosFor(amountGrams), nil
+		}
+
+		if entry.FDCID != nil {
+			profile, err := getNutrientProfile(ctx, tx, *entry.FDCID)
+			if err != nil {
+				return model.Macros{}, err
+			}
+			if profile != nil {
+				return profile.MacrosFor(amountGrams), nil
+			}
+		}
+	}
+
+	ratio := amountGrams / entry.AmountGrams
+	return model.Macros{
+		Calories: scaleAmount(entry.CalculatedCalories, ratio),
+		Protein:  scaleAmount(entry.CalculatedProtein, ratio),
+		Fat:      scaleAmount(entry.CalculatedFat, ratio),
+		Carbs:    scaleAmount(entry.CalculatedCarbs, ratio),
+	}, nil
+}
+
+// getNutrientProfile loads the per-100 g profile of a USDA food
+func getNutrientProfile(ctx context.Context, tx *sql.Tx, fdcID int) (*model.NutrientProfile, error) {
+	var exists bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM nutrition.foods WHERE fdc_id = $1)", fdcID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check food: %w", err)
+	}
+	if !exists {
+		return nil, nil // Food not found
+	}
+
+	query := `
+		SELECT nutrient_id, unit_name, amount
+		FROM nutrition.food_nutrients
+		WHERE fdc_id = $1 AND nutrient_id = ANY($2)
+	`
+
+	rows, err := tx.QueryContext(ctx, query, fdcID, pq.Array(model.ProfileNutrientIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nutrients: %w", err)
+	}
+	defer rows.Close()
+
+	var nutrients []*model.FoodNutrient
+	for rows.Next() {
+		nutrient := model.FoodNutrient{FDCID: fdcID}
+		if err := rows.Scan(&nutrient.NutrientID, &nutrient.UnitName, &nutrient.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan nutrient: %w", err)
+		}
+		nutrients = append(nutrients, &nutrient)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating nutrient rows: %w", err)
+	}
+
+	return model.NewNutrientProfile(nutrients), nil
+}
+
+// scaleAmount multiplies an optional nutrient amount, rounded to 0.01
+func scaleAmount(amount *float64, ratio float64) *float64 {
+	if amount == nil {
+		return nil
+	}
+	scaled := math.Round(*amount*ratio*100) / 100
+	return &scaled
+}
+
 // DeleteFoodEntry deletes a food entry by ID
 func (r *diaryRepository) DeleteFoodEntry(ctx context.Context, id uuid.UUID) error {
 	query := "DELETE FROM diary.food_entries WHERE id = $1"
@@ -244,10 +379,12 @@ func (r *diaryRepository) DeleteFoodEntriesByUserAndDate(ctx context.Context, us
 	return nil
 }
 
-// GetDaySummary calculates nutritional summary for a specific day
-func (r *diaryRepository) GetDaySummary(ctx context.Context, userID uuid.UUID, date time.Time) (*model.DaySummary, error) {
+// GetDailySummaries calculates the nutritional summary of each day with entries in a
+// date period, keyed by date (YYYY-MM-DD). Days without entries are missing.
+func (r *diaryRepository) GetDailySummaries(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, nutrients model.NutrientFields) (map[string]*model.DaySummary, error) {
 	query := `
-		SELECT 
+		SELECT
+			date,
 			COALESCE(SUM(calculated_calories), 0) as total_calories,
 			COALESCE(SUM(calculated_protein), 0) as total_protein,
 			COALESCE(SUM(calculated_fat), 0) as total_fat,
@@ -255,32 +392,54 @@ func (r *diaryRepository) GetDaySummary(ctx context.Context, userID uuid.UUID, d
 			COUNT(DISTINCT meal_type) as meal_count,
 			COUNT(*) as food_count
 		FROM diary.food_entries
-		WHERE user_id = $1 AND date = $2
+		WHERE user_id = $1 AND date >= $2 AND date <= $3
+		GROUP BY date
 	`
-	
-	var summary model.DaySummary
-	err := r.db.QueryRowContext(ctx, query, userID, date).Scan(
-		&summary.TotalCalories,
-		&summary.TotalProtein,
-		&summary.TotalFat,
-		&summary.TotalCarbs,
-		&summary.MealCount,
-		&summary.FoodCount,
-	)
-	
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// Return empty summary if no entries
-			return &model.DaySummary{}, nil
+		return nil, fmt.Errorf("failed to query daily summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := make(map[string]*model.DaySummary)
+	for rows.Next() {
+		var date time.Time
+		var summary model.DaySummary
+		err := rows.Scan(
+			&date,
+			&summary.TotalCalories,
+			&summary.TotalProtein,
+			&summary.TotalFat,
+			&summary.TotalCarbs,
+			&summary.MealCount,
+			&summary.FoodCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily summary: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get day summary: %w", err)
+		summaries[date.Format("2006-01-02")] = &summary
 	}
-	
-	return &summary, nil
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily summary rows: %w", err)
+	}
+
+	totals, err := r.getNutrientTotals(ctx, userID, startDate, endDate, nutrients, true)
+	if err != nil {
+		return nil, err
+	}
+	for date, dayTotals := range totals {
+		if summary, ok := summaries[date]; ok {
+			summary.Nutrients = dayTotals
+		}
+	}
+
+	return summaries, nil
 }
 
 // GetPeriodSummary calculates nutritional summary for a date period
-func (r *diaryRepository) GetPeriodSummary(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) (*model.DaySummary, error) {
+func (r *diaryRepository) GetPeriodSummary(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, nutrients model.NutrientFields) (*model.DaySummary, error) {
 	query := `
 		SELECT 
 			COALESCE(SUM(calculated_calories), 0) as total_calories,
@@ -310,10 +469,115 @@ func (r *diaryRepository) GetPeriodSummary(ctx context.Context, userID uuid.UUID
 		}
 		return nil, fmt.Errorf("failed to get period summary: %w", err)
 	}
+
+	totals, err := r.getNutrientTotals(ctx, userID, startDate, endDate, nutrients, false)
+	if err != nil {
+		return nil, err
+	}
+	if totals != nil {
+		summary.Nutrients = totals[""]
+		if summary.Nutrients == nil {
+			summary.Nutrients = []*model.NutrientTotal{}
+		}
+	}
 	
 	return &summary, nil
 }
 
+// getNutrientTotals sums nutrients of entries in a date period, keyed by date
+// (YYYY-MM-DD) when byDate is set and by "" for the whole period otherwise. Energy,
+// protein, fat and carbs come from the calculated entry values so they match the
+// summary totals; all other nutrients come from USDA snapshots scaled to each
+// entry's amount, with alternative USDA nutrients counted once under the preferred
+// one. Custom foods only contribute to the calculated values. Returns nil when no
+// nutrients are requested.
+func (r *diaryRepository) getNutrientTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, fields model.NutrientFields, byDate bool) (map[string][]*model.NutrientTotal, error) {
+	if fields == "" || fields == model.NutrientFieldsNone {
+		return nil, nil
+	}
+
+	// Calculated values are reported under the USDA nutrients they are based on
+	query := `
+		WITH entries AS (
+			SELECT *
+			FROM diary.food_entries
+			WHERE user_id = $1 AND date >= $2 AND date <= $3
+		),
+		snapshot AS (
+			SELECT DISTINCT ON (e.id, COALESCE(a.preferred, (n->>'nutrient_id')::int))
+				e.date,
+				COALESCE(a.preferred, (n->>'nutrient_id')::int) AS nutrient_id,
+				n->>'name' AS name,
+				n->>'unit' AS unit,
+				(n->>'amount')::double precision * e.amount_grams / 100 AS amount
+			FROM entries e
+			CROSS JOIN LATERAL jsonb_array_elements(e.nutrients_per_100g) n
+			LEFT JOIN unnest($5::int[], $6::int[], $7::int[]) AS a(nutrient_id, preferred, rank)
+				ON a.nutrient_id = (n->>'nutrient_id')::int
+			ORDER BY e.id, COALESCE(a.preferred, (n->>'nutrient_id')::int), a.rank
+		),
+		totals AS (
+			SELECT date, nutrient_id, name, unit, amount
+			FROM snapshot
+			WHERE nutrient_id <> ALL($8::int[])
+			UNION ALL
+			SELECT e.date, m.nutrient_id, m.name, m.unit, m.amount
+			FROM entries e
+			CROSS JOIN LATERAL (VALUES
+				(1008, 'Energy', 'KCAL', e.calculated_calories),
+				(1003, 'Protein', 'G', e.calculated_protein),
+				(1004, 'Total lipid (fat)', 'G', e.calculated_fat),
+				(1005, 'Carbohydrate, by difference', 'G', e.calculated_carbs)
+			) AS m(nutrient_id, name, unit, amount)
+		)
+		SELECT
+			CASE WHEN $9 THEN date END AS day,
+			nutrient_id,
+			MAX(name) AS name,
+			MAX(unit) AS unit,
+			COALESCE(SUM(amount), 0) AS amount,
+			COUNT(amount) AS entry_count
+		FROM totals
+		WHERE COALESCE(cardinality($4::int[]), 0) = 0 OR nutrient_id = ANY($4)
+		GROUP BY 1, nutrient_id
+		HAVING COUNT(amount) > 0
+		ORDER BY 1, nutrient_id
+	`
+
+	alternates, preferred, ranks := model.NutrientAlternates()
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate, pq.Array(fields.NutrientIDs()),
+		pq.Array(alternates), pq.Array(preferred), pq.Array(ranks), pq.Array(model.MacroNutrientIDs), byDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nutrient totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string][]*model.NutrientTotal)
+	for rows.Next() {
+		var total model.NutrientTotal
+		var day sql.NullTime
+		var name, unit sql.NullString
+		if err := rows.Scan(&day, &total.NutrientID, &name, &unit, &total.Amount, &total.EntryCount); err != nil {
+			return nil, fmt.Errorf("failed to scan nutrient total: %w", err)
+		}
+		total.Name = name.String
+		total.Unit = unit.String
+		total.Amount = math.Round(total.Amount*100) / 100
+
+		key := ""
+		if day.Valid {
+			key = day.Time.Format("2006-01-02")
+		}
+		totals[key] = append(totals[key], &total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating nutrient total rows: %w", err)
+	}
+
+	return totals, nil
+}
+
 // CopyFoodEntries copies food entries from one date to another for a user
 func (r *diaryRepository) CopyFoodEntries(ctx context.Context, userID uuid.UUID, sourceDate, targetDate time.Time) error {
 	// Use transaction to ensure atomicity
@@ -335,12 +599,14 @@ func (r *diaryRepository) CopyFoodEntries(ctx context.Context, userID uuid.UUID,
 		INSERT INTO diary.food_entries (
 			id, user_id, date, meal_type, fdc_id, custom_food_name,
 			amount_grams, calculated_calories, calculated_protein,
-			calculated_fat, calculated_carbs, created_at
+			calculated_fat, calculated_carbs, food_description,
+			nutrients_per_100g, nutrients_overridden, created_at
 		)
 		SELECT 
 			gen_random_uuid(), user_id, $3, meal_type, fdc_id, custom_food_name,
 			amount_grams, calculated_calories, calculated_protein,
-			calculated_fat, calculated_carbs, NOW()
+			calculated_fat, calculated_carbs, food_description,
+			nutrients_per_100g, nutrients_overridden, NOW()
 		FROM diary.food_entries
 		WHERE user_id = $1 AND date = $2
 	`
@@ -5,13 +5,13 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
 	"github.com/yourusername/auth-service/internal/model"
-	_ "github.com/lib/pq"
 )
 
 // FoodRepository defines the interface for food data access
 type FoodRepository interface {
-	SearchFoods(ctx context.Context, query string, limit, offset int) ([]*model.FoodWithNutrients, int, error)
+	SearchFoods(ctx context.Context, query string, limit, offset int, fields model.NutrientFields) ([]*model.FoodWithNutrients, int, error)
 	GetFoodByID(ctx context.Context, fdcID int) (*model.FoodWithNutrients, error)
 	Close() error
 }
@@ -26,45 +26,53 @@ func NewFoodRepository(db *sql.DB) FoodRepository {
 	return &foodRepository{db: db}
 }
 
-// SearchFoods searches for foods by description with pagination
-func (r *foodRepository) SearchFoods(ctx context.Context, query string, limit, offset int) ([]*model.FoodWithNutrients, int, error) {
+// foodSearchTerms parses the search query once per statement: all_terms requires every
+// word, any_term matches foods containing at least one of them
+const foodSearchTerms = `
+	WITH q AS (
+		SELECT
+			plainto_tsquery('english', $1) AS all_terms,
+			replace(plainto_tsquery('english', $1)::text, ' & ', ' | ')::tsquery AS any_term
+	)
+`
+
+// foodSearchMatch selects foods sharing a stemmed word with the query, or containing
+// a word similar to it (typos) via pg_trgm
+const foodSearchMatch = `(f.search_vector @@ q.any_term OR $1 <% f.description)`
+
+// SearchFoods searches for foods by description with pagination, most relevant first
+func (r *foodRepository) SearchFoods(ctx context.Context, query string, limit, offset int, fields model.NutrientFields) ([]*model.FoodWithNutrients, int, error) {
 	// First, get total count for pagination
 	var total int
-	countQuery := `
-		SELECT COUNT(*) 
-		FROM nutrition.foods 
-		WHERE description ILIKE $1
-	`
-	err := r.db.QueryRowContext(ctx, countQuery, "%"+query+"%").Scan(&total)
+	countQuery := foodSearchTerms + `
+		SELECT COUNT(*)
+		FROM nutrition.foods f, q
+		WHERE ` + foodSearchMatch
+	err := r.db.QueryRowContext(ctx, countQuery, query).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count foods: %w", err)
 	}
 
-	// Then, get paginated results
-	searchQuery := `
-		SELECT 
-			f.fdc_id, 
-			f.description, 
-			f.data_type, 
-			f.food_class, 
+	// Then, get paginated results. Foods containing every word come first, then
+	// by full-text rank (favoring shorter descriptions) and trigram similarity.
+	searchQuery := foodSearchTerms + `
+		SELECT
+			f.fdc_id,
+			f.description,
+			f.data_type,
+			f.food_class,
 			f.publication_date
-		FROM nutrition.foods f
-		WHERE f.description ILIKE $1
-		ORDER BY 
-			CASE 
-				WHEN f.description ILIKE $2 THEN 0
-				WHEN f.description ILIKE $3 THEN 1
-				ELSE 2
-			END,
-			f.description
-		LIMIT $4 OFFSET $5
+		FROM nutrition.foods f, q
+		WHERE ` + foodSearchMatch + `
+		ORDER BY
+			(f.search_vector @@ q.all_terms) DESC,
+			ts_rank_cd(f.search_vector, q.any_term, 1) + word_similarity($1, f.description) DESC,
+			f.description,
+			f.fdc_id
+		LIMIT $2 OFFSET $3
 	`
-	
-	// Prepare search patterns for relevance sorting
-	exactPattern := query + "%"
-	containsPattern := "%" + query + "%"
-	
-	rows, err := r.db.QueryContext(ctx, searchQuery, containsPattern, exactPattern, containsPattern, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, searchQuery, query, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to search foods: %w", err)
 	}
@@ -90,42 +98,76 @@ func (r *foodRepository) SearchFoods(ctx context.Context, query string, limit, o
 		return nil, 0, fmt.Errorf("error iterating food rows: %w", err)
 	}
 
-	// Get nutrients for each food
 	result := make([]*model.FoodWithNutrients, 0, len(foods))
 	for _, food := range foods {
-		nutrients, err := r.getFoodNutrients(ctx, food.FDCID)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to get nutrients for food %d: %w", food.FDCID, err)
-		}
-		
 		result = append(result, &model.FoodWithNutrients{
-			Food:     food,
-			Nutrients: nutrients,
+			Food:      food,
+			Nutrients: []*model.FoodNutrient{},
 		})
 	}
 
+	if len(foods) == 0 {
+		return result, total, nil
+	}
+
+	// Load nutrients for the whole page in one query. The profile is always
+	// returned, so its nutrients are loaded even when none are requested.
+	fdcIDs := make([]int, len(foods))
+	for i, food := range foods {
+		fdcIDs[i] = food.FDCID
+	}
+
+	var nutrientIDs []int
+	switch fields {
+	case model.NutrientFieldsNone, model.NutrientFieldsCore:
+		nutrientIDs = model.ProfileNutrientIDs
+	case model.NutrientFieldsExtended:
+		nutrientIDs = model.ExtendedNutrientIDs
+	}
+
+	nutrients, err := r.getFoodNutrients(ctx, fdcIDs, nutrientIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get nutrients: %w", err)
+	}
+
+	for _, item := range result {
+		foodNutrients := nutrients[item.Food.FDCID]
+		item.Profile = model.NewNutrientProfile(foodNutrients)
+
+		switch fields {
+		case model.NutrientFieldsAll:
+			if foodNutrients != nil {
+				item.Nutrients = foodNutrients
+			}
+		case model.NutrientFieldsCore, model.NutrientFieldsExtended:
+			item.Nutrients = filterNutrients(foodNutrients, fields.NutrientIDs())
+		}
+	}
+
 	return result, total, nil
 }
 
-// getFoodNutrients retrieves nutrients for a specific food
-func (r *foodRepository) getFoodNutrients(ctx context.Context, fdcID int) ([]*model.FoodNutrient, error) {
+// getFoodNutrients retrieves nutrients for the given foods, grouped by FDC ID.
+// When nutrientIDs is empty all nutrients are returned.
+func (r *foodRepository) getFoodNutrients(ctx context.Context, fdcIDs []int, nutrientIDs []int) (map[int][]*model.FoodNutrient, error) {
 	query := `
 		SELECT 
 			id, fdc_id, nutrient_id, nutrient_name, nutrient_number,
 			unit_name, amount, data_points, min_val, max_val, median,
 			derivation_code, derivation_desc
 		FROM nutrition.food_nutrients
-		WHERE fdc_id = $1
-		ORDER BY nutrient_id
+		WHERE fdc_id = ANY($1)
+			AND (COALESCE(cardinality($2::int[]), 0) = 0 OR nutrient_id = ANY($2))
+		ORDER BY fdc_id, nutrient_id
 	`
 	
-	rows, err := r.db.QueryContext(ctx, query, fdcID)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(fdcIDs), pq.Array(nutrientIDs))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query nutrients: %w", err)
 	}
 	defer rows.Close()
 
-	var nutrients []*model.FoodNutrient
+	nutrients := make(map[int][]*model.FoodNutrient, len(fdcIDs))
 	for rows.Next() {
 		var nutrient model.FoodNutrient
 		err := rows.Scan(
@@ -146,7 +188,7 @@ func (r *foodRepository) getFoodNutrients(ctx context.Context, fdcID int) ([]*mo
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan nutrient: %w", err)
 		}
-		nutrients = append(nutrients, &nutrient)
+		nutrients[nutrient.FDCID] = append(nutrients[nutrient.FDCID], &nutrient)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -156,6 +198,20 @@ func (r *foodRepository) getFoodNutrients(ctx context.Context, fdcID int) ([]*mo
 	return nutrients, nil
 }
 
+// filterNutrients keeps only the nutrients with the given IDs
+func filterNutrients(nutrients []*model.FoodNutrient, nutrientIDs []int) []*model.FoodNutrient {
+	filtered := make([]*model.FoodNutrient, 0, len(nutrientIDs))
+	for _, nutrient := range nutrients {
+		for _, id := range nutrientIDs {
+			if nutrient.NutrientID == id {
+				filtered = append(filtered, nutrient)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
 // GetFoodByID retrieves a food by its FDC ID with nutrients
 func (r *foodRepository) GetFoodByID(ctx context.Context, fdcID int) (*model.FoodWithNutrients, error) {
 	// Get food
@@ -186,14 +242,15 @@ func (r *foodRepository) GetFoodByID(ctx context.Context, fdcID int) (*model.Foo
 	}
 
 	// Get nutrients
-	nutrients, err := r.getFoodNutrients(ctx, fdcID)
+	nutrients, err := r.getFoodNutrients(ctx, []int{fdcID}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nutrients: %w", err)
 	}
 
 	return &model.FoodWithNutrients{
-		Food:     &food,
-		Nutrients: nutrients,
+		Food:      &food,
+		Nutrients: nutrients[fdcID],
+		Profile:   model.NewNutrientProfile(nutrients[fdcID]),
 	}, nil
 }
 
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/model"
+)
+
+// GoalRepository defines the interface for nutrition goal data access
+type GoalRepository interface {
+	SetGoal(ctx context.Context, goal *model.NutritionGoal) error
+	ListGoals(ctx context.Context, userID uuid.UUID) ([]*model.NutritionGoal, error)
+	GetGoalsForPeriod(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*model.NutritionGoal, error)
+	DeleteGoal(ctx context.Context, userID uuid.UUID, effectiveFrom time.Time) (*model.NutritionGoal, error)
+	Close() error
+}
+
+// goalRepository implements GoalRepository with PostgreSQL
+type goalRepository struct {
+	db *sql.DB
+}
+
+// NewGoalRepository creates a new nutrition goal repository
+func NewGoalRepository(db *sql.DB) GoalRepository {
+	return &goalRepository{db: db}
+}
+
+// goalColumns lists the columns selected for a nutrition goal
+const goalColumns = `
+	id, user_id, effective_from, calories,
+	protein_grams, protein_percent, fat_grams, fat_percent,
+	carbs_grams, carbs_percent, micronutrients, created_at, updated_at
+`
+
+// scanGoal scans a single nutrition goal row
+func scanGoal(row rowScanner) (*model.NutritionGoal, error) {
+	var goal model.NutritionGoal
+	var protein, fat, carbs model.MacroTarget
+	var micronutrients []byte
+	err := row.Scan(
+		&goal.ID,
+		&goal.UserID,
+		&goal.EffectiveFrom,
+		&goal.Calories,
+		&protein.Grams,
+		&protein.Percent,
+		&fat.Grams,
+		&fat.Percent,
+		&carbs.Grams,
+		&carbs.Percent,
+		&micronutrients,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.Protein = macroTargetOrNil(protein)
+	goal.Fat = macroTargetOrNil(fat)
+	goal.Carbs = macroTargetOrNil(carbs)
+
+	if micronutrients != nil {
+		if err := json.Unmarshal(micronutrients, &goal.Micronutrients); err != nil {
+			return nil, fmt.Errorf("failed to decode micronutrient targets: %w", err)
+		}
+	}
+
+	return &goal, nil
+}
+
+// macroTargetOrNil returns nil for a target without grams or percent
+func macroTargetOrNil(target model.MacroTarget) *model.MacroTarget {
+	if target.Grams == nil && target.Percent == nil {
+		return nil
+	}
+	return &target
+}
+
+// SetGoal creates the goal starting at goal.EffectiveFrom or replaces the one
+// already starting on that date
+func (r *goalRepository) SetGoal(ctx context.Context, goal *model.NutritionGoal) error {
+	var micronutrients interface{}
+	if len(goal.Micronutrients) > 0 {
+		encoded, err := json.Marshal(goal.Micronutrients)
+		if err != nil {
+			return fmt.Errorf("failed to encode micronutrient targets: %w", err)
+		}
+		micronutrients = encoded
+	}
+
+	protein := goal.Protein
+	if protein == nil {
+		protein = &model.MacroTarget{}
+	}
+	fat := goal.Fat
+	if fat == nil {
+		fat = &model.MacroTarget{}
+	}
+	carbs := goal.Carbs
+	if carbs == nil {
+		carbs = &model.MacroTarget{}
+	}
+
+	query := `
+		INSERT INTO diary.nutrition_goals (
+			id, user_id, effective_from, calories,
+			protein_grams, protein_percent, fat_grams, fat_percent,
+			carbs_grams, carbs_percent, micronutrients, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		ON CONFLICT (user_id, effective_from) DO UPDATE SET
+			calories = EXCLUDED.calories,
+			protein_grams = EXCLUDED.protein_grams,
+			protein_percent = EXCLUDED.protein_percent,
+			fat_grams = EXCLUDED.fat_grams,
+			fat_percent = EXCLUDED.fat_percent,
+			carbs_grams = EXCLUDED.carbs_grams,
+			carbs_percent = EXCLUDED.carbs_percent,
+			micronutrients = EXCLUDED.micronutrients,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		goal.ID,
+		goal.UserID,
+		goal.EffectiveFrom,
+		goal.Calories,
+		protein.Grams,
+		protein.Percent,
+		fat.Grams,
+		fat.Percent,
+		carbs.Grams,
+		carbs.Percent,
+		micronutrients,
+	).Scan(&goal.ID, &goal.CreatedAt, &goal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set nutrition goal: %w", err)
+	}
+
+	return nil
+}
+
+// ListGoals returns the goal history of a user, oldest first
+func (r *goalRepository) ListGoals(ctx context.Context, userID uuid.UUID) ([]*model.NutritionGoal, error) {
+	query := "SELECT " + goalColumns + " FROM diary.nutrition_goals WHERE user_id = $1 ORDER BY effective_from"
+	return r.queryGoals(ctx, query, userID)
+}
+
+// GetGoalsForPeriod returns the goal active at startDate followed by all goals
+// starting within the period, oldest first
+func (r *goalRepository) GetGoalsForPeriod(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*model.NutritionGoal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM diary.nutrition_goals
+		WHERE user_id = $1
+			AND effective_from <= $3
+			AND effective_from >= COALESCE((
+				SELECT MAX(effective_from)
+				FROM diary.nutrition_goals
+				WHERE user_id = $1 AND effective_from <= $2
+			), '-infinity'::date)
+		ORDER BY effective_from
+	`
+	return r.queryGoals(ctx, query, userID, startDate, endDate)
+}
+
+// queryGoals runs a query returning goal rows
+func (r *goalRepository) queryGoals(ctx context.Context, query string, args ...interface{}) ([]*model.NutritionGoal, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nutrition goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []*model.NutritionGoal{}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan nutrition goal: %w", err)
+		}
+		goals = append(goals, goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating nutrition goal rows: %w", err)
+	}
+
+	return goals, nil
+}
+
+// DeleteGoal removes the goal starting at effectiveFrom and returns it, or nil
+// when the user has no goal starting on that date
+func (r *goalRepository) DeleteGoal(ctx context.Context, userID uuid.UUID, effectiveFrom time.Time) (*model.NutritionGoal, error) {
+	query := "DELETE FROM diary.nutrition_goals WHERE user_id = $1 AND effective_from = $2 RETURNING " + goalColumns
+
+	goal, err := scanGoal(r.db.QueryRowContext(ctx, query, userID, effectiveFrom))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Goal not found
+		}
+		return nil, fmt.Errorf("failed to delete nutrition goal: %w", err)
+	}
+
+	return goal, nil
+}
+
+// Close closes the database connection
+func (r *goalRepository) Close() error {
+	return r.db.Close()
+}
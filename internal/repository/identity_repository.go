@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/auth-service/internal/model"
+)
+
+// ErrIdentityAlreadyLinked is returned when an external identity belongs to a user already
+var ErrIdentityAlreadyLinked = errors.New("identity is already linked to a user")
+
+// IdentityRepository defines the interface for external identity data access
+type IdentityRepository interface {
+	CreateIdentity(ctx context.Context, identity *model.Identity) error
+	CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.Identity) error
+	GetIdentity(ctx context.Context, issuer, subject string) (*model.Identity, error)
+	ListIdentities(ctx context.Context, userID uuid.UUID) ([]*model.Identity, error)
+	DeleteIdentity(ctx context.Context, userID, id uuid.UUID) (bool, error)
+	UpdateIdentityLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error
+	CreateOIDCState(ctx context.Context, state *model.OIDCState) error
+	ConsumeOIDCState(ctx context.Context, stateHash, bindingHash string) (*model.OIDCState, error)
+	PurgeExpiredOIDCStates(ctx context.Context, expiredBefore time.Time) (int64, error)
+	Close() error
+}
+
+// identityRepository implements IdentityRepository with PostgreSQL
+type identityRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityRepository creates a new identity repository
+func NewIdentityRepository(db *sql.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+// identityColumns lists the columns selected for an identity
+const identityColumns = `
+	id, user_id, provider, issuer, subject, email, last_login_at, created_at
+`
+
+// scanIdentity scans a single identity row
+func scanIdentity(row rowScanner) (*model.Identity, error) {
+	var identity model.Identity
+	err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.Email,
+		&identity.LastLoginAt,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// identityExecer is implemented by *sql.DB and *sql.Tx
+type identityExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertIdentity inserts an identity, mapping unique violations to ErrIdentityAlreadyLinked
+func insertIdentity(ctx context.Context, db identityExecer, identity *model.Identity) error {
+	query := `
+		INSERT INTO auth.identities (
+			id, user_id, provider, issuer, subject, email, last_login_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		identity.ID,
+		identity.UserID,
+		identity.Provider,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		identity.LastLoginAt,
+		identity.CreatedAt,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrIdentityAlreadyLinked
+		}
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return nil
+}
+
+// CreateIdentity links an external identity to an existing user
+func (r *identityRepository) CreateIdentity(ctx context.Context, identity *model.Identity) error {
+	return insertIdentity(ctx, r.db, identity)
+}
+
+// CreateUserWithIdentity registers a new user together with its first external identity
+func (r *identityRepository) CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.Identity) error {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	userQuery := `
+		INSERT INTO auth.users (
+			id, email, password_hash, first_name, last_name, is_active, is_verified, role
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, userQuery,
+		user.ID,
+		user.Email,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.IsActive,
+		user.IsVerified,
+		user.Role,
+	).Scan(&user.CreatedAt, &user.UpdatedAt)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := insertIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetIdentity retrieves an identity by issuer and subject
+func (r *identityRepository) GetIdentity(ctx context.Context, issuer, subject string) (*model.Identity, error) {
+	query := "SELECT " + identityColumns + " FROM auth.identities WHERE issuer = $1 AND subject = $2"
+
+	identity, err := scanIdentity(r.db.QueryRowContext(ctx, query, issuer, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Identity not found
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+// ListIdentities returns the identities linked to a user
+func (r *identityRepository) ListIdentities(ctx context.Context, userID uuid.UUID) ([]*model.Identity, error) {
+	query := "SELECT " + identityColumns + " FROM auth.identities WHERE user_id = $1 ORDER BY created_at"
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := make([]*model.Identity, 0)
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating identities: %w", err)
+	}
+
+	return identities, nil
+}
+
+// DeleteIdentity unlinks an identity of the user, reporting whether it existed
+func (r *identityRepository) DeleteIdentity(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	query := "DELETE FROM auth.identities WHERE id = $1 AND user_id = $2"
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete identity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateIdentityLastLogin records the time of the last login through an identity
+func (r *identityRepository) UpdateIdentityLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	query := "UPDATE auth.identities SET last_login_at = $1 WHERE id = $2"
+
+	_, err := r.db.ExecContext(ctx, query, loginAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update identity last login: %w", err)
+	}
+
+	return nil
+}
+
+// CreateOIDCState stores a pending authorization request
+func (r *identityRepository) CreateOIDCState(ctx context.Context, state *model.OIDCState) error {
+	query := `
+		INSERT INTO auth.oidc_states (
+			id, state_hash, binding_hash, provider, code_verifier, nonce, user_id, session_id, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		state.ID,
+		state.StateHash,
+		state.BindingHash,
+		state.Provider,
+		state.CodeVerifier,
+		state.Nonce,
+		state.UserID,
+		state.SessionID,
+		state.ExpiresAt,
+		state.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create OIDC state: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeOIDCState deletes and returns an unexpired authorization request so each state
+// can be used only once. The request is only found from the browser that started it.
+func (r *identityRepository) ConsumeOIDCState(ctx context.Context, stateHash, bindingHash string) (*model.OIDCState, error) {
+	query := `
+		DELETE FROM auth.oidc_states
+		WHERE state_hash = $1 AND binding_hash = $2 AND expires_at > NOW()
+		RETURNING id, state_hash, binding_hash, provider, code_verifier, nonce, user_id, session_id, expires_at, created_at
+	`
+
+	var state model.OIDCState
+	err := r.db.QueryRowContext(ctx, query, stateHash, bindingHash).Scan(
+		&state.ID,
+		&state.StateHash,
+		&state.BindingHash,
+		&state.Provider,
+		&state.CodeVerifier,
+		&state.Nonce,
+		&state.UserID,
+		&state.SessionID,
+		&state.ExpiresAt,
+		&state.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // State not found or expired
+		}
+		return nil, fmt.Errorf("failed to consume OIDC state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// PurgeExpiredOIDCStates deletes authorization requests that were never completed
+func (r *identityRepository) PurgeExpiredOIDCStates(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM auth.oidc_states WHERE expires_at < $1", expiredBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge OIDC states: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// Close closes the database connection
+func (r *identityRepository) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,535 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/auth-service/internal/model"
+)
+
+// ErrRefreshTokenAlreadyRotated is returned when a refresh token was rotated concurrently
+var ErrRefreshTokenAlreadyRotated = errors.New("refresh token already rotated")
+
+// TokenRepository defines the interface for token data access
+type TokenRepository interface {
+	// Refresh tokens
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID uuid.UUID, newToken *model.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeAllUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+
+	// Sessions
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*model.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+	IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+
+	// Password reset tokens
+	CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*uuid.UUID, error)
+
+	// Email verification tokens
+	CreateEmailVerificationToken(ctx context.Context, token *model.EmailVerificationToken) error
+	GetLastEmailVerificationSentAt(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	VerifyEmail(ctx context.Context, tokenHash string) (*uuid.UUID, error)
+
+	// Access tokens
+	RevokeAccessToken(ctx context.Context, token *model.RevokedAccessToken) error
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+
+	// Maintenance
+	PurgeExpiredTokens(ctx context.Context, expiredBefore time.Time) (*model.TokenPurgeResult, error)
+
+	Close() error
+}
+
+// tokenRepository implements TokenRepository with PostgreSQL
+type tokenRepository struct {
+	db *sql.DB
+}
+
+// NewTokenRepository creates a new token repository
+func NewTokenRepository(db *sql.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+// refreshTokenColumns lists the columns selected for a refresh token
+const refreshTokenColumns = `
+	id, user_id, token_hash, device_info, host(ip_address), family_id,
+	replaced_by, expires_at, revoked_at, created_at
+`
+
+// scanRefreshToken scans a single refresh token row
+func scanRefreshToken(row rowScanner) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.DeviceInfo,
+		&token.IPAddress,
+		&token.FamilyID,
+		&token.ReplacedBy,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// insertRefreshToken inserts a refresh token row within a transaction
+func insertRefreshToken(ctx context.Context, tx *sql.Tx, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO auth.refresh_tokens (
+			id, user_id, token_hash, device_info, ip_address, family_id, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.DeviceInfo,
+		token.IPAddress,
+		token.FamilyID,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	return err
+}
+
+// CreateRefreshToken stores a refresh token for a new login, revoking any active token for the same device
+func (r *tokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	revokeQuery := `
+		UPDATE auth.refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND device_info IS NOT DISTINCT FROM $2 AND revoked_at IS NULL
+	`
+	_, err = tx.ExecContext(ctx, revokeQuery, token.UserID, token.DeviceInfo)
+	if err != nil {
+		return fmt.Errorf("failed to revoke previous device tokens: %w", err)
+	}
+
+	if err := insertRefreshToken(ctx, tx, token); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by its hash
+func (r *tokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	query := "SELECT " + refreshTokenColumns + " FROM auth.refresh_tokens WHERE token_hash = $1"
+
+	token, err := scanRefreshToken(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Token not found
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken revokes the old refresh token and stores its replacement atomically
+func (r *tokenRepository) RotateRefreshToken(ctx context.Context, oldID uuid.UUID, newToken *model.RefreshToken) error {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only an active token can be rotated; a concurrent rotation loses here
+	revokeQuery := `
+		UPDATE auth.refresh_tokens SET revoked_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	result, err := tx.ExecContext(ctx, revokeQuery, oldID, newToken.ID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrRefreshTokenAlreadyRotated
+	}
+
+	if err := insertRefreshToken(ctx, tx, newToken); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every active refresh token in a family
+func (r *tokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := "UPDATE auth.refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL"
+
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllUserRefreshTokens revokes every active refresh token of a user
+func (r *tokenRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	query := "UPDATE auth.refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveSessions lists refresh token families of a user that still hold an active token
+func (r *tokenRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*model.Session, error) {
+	query := `
+		SELECT
+			t.family_id, t.device_info, host(t.ip_address),
+			f.started_at, t.created_at, t.expires_at
+		FROM auth.refresh_tokens t
+		JOIN (
+			SELECT family_id, MIN(created_at) AS started_at
+			FROM auth.refresh_tokens
+			WHERE user_id = $1
+			GROUP BY family_id
+		) f ON f.family_id = t.family_id
+		WHERE t.user_id = $1 AND t.revoked_at IS NULL AND t.expires_at > NOW()
+		ORDER BY t.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*model.Session{}
+	for rows.Next() {
+		var session model.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.DeviceInfo,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.RefreshedAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session rows: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeUserSession revokes one session of a user, reporting whether it was active
+func (r *tokenRepository) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE auth.refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// IsSessionActive reports whether a session still holds an active refresh token
+func (r *tokenRepository) IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM auth.refresh_tokens
+			WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		)
+	`
+
+	var active bool
+	err := r.db.QueryRowContext(ctx, query, sessionID).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return active, nil
+}
+
+// CreatePasswordResetToken stores a reset token, invalidating earlier unused tokens of the user
+func (r *tokenRepository) CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	invalidateQuery := "UPDATE auth.password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL"
+	_, err = tx.ExecContext(ctx, invalidateQuery, token.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO auth.password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = tx.ExecContext(ctx, insertQuery,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a valid reset token, sets the new password hash and revokes all
+// refresh tokens of the user. It returns nil if the token is unknown, used or expired.
+func (r *tokenRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*uuid.UUID, error) {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	consumeQuery := `
+		UPDATE auth.password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+	var userID uuid.UUID
+	err = tx.QueryRowContext(ctx, consumeQuery, tokenHash).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Token not valid
+		}
+		return nil, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	updateQuery := "UPDATE auth.users SET password_hash = $1 WHERE id = $2 AND deleted_at IS NULL"
+	_, err = tx.ExecContext(ctx, updateQuery, passwordHash, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	revokeQuery := "UPDATE auth.refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+	_, err = tx.ExecContext(ctx, revokeQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &userID, nil
+}
+
+// CreateEmailVerificationToken stores an email verification token
+func (r *tokenRepository) CreateEmailVerificationToken(ctx context.Context, token *model.EmailVerificationToken) error {
+	query := `
+		INSERT INTO auth.email_verification_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastEmailVerificationSentAt returns when the latest verification token was issued to a user
+func (r *tokenRepository) GetLastEmailVerificationSentAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	query := "SELECT MAX(created_at) FROM auth.email_verification_tokens WHERE user_id = $1"
+
+	var sentAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&sentAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last verification email time: %w", err)
+	}
+
+	if !sentAt.Valid {
+		return nil, nil
+	}
+	return &sentAt.Time, nil
+}
+
+// VerifyEmail consumes a valid verification token and marks the user as verified.
+// It returns nil if the token is unknown, used or expired.
+func (r *tokenRepository) VerifyEmail(ctx context.Context, tokenHash string) (*uuid.UUID, error) {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	consumeQuery := `
+		UPDATE auth.email_verification_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+	var userID uuid.UUID
+	err = tx.QueryRowContext(ctx, consumeQuery, tokenHash).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Token not valid
+		}
+		return nil, fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+
+	verifyQuery := "UPDATE auth.users SET is_verified = TRUE WHERE id = $1"
+	_, err = tx.ExecContext(ctx, verifyQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify user: %w", err)
+	}
+
+	// Remaining links for the same user are no longer needed
+	invalidateQuery := "UPDATE auth.email_verification_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL"
+	_, err = tx.ExecContext(ctx, invalidateQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &userID, nil
+}
+
+// RevokeAccessToken adds an access token jti to the revocation list
+func (r *tokenRepository) RevokeAccessToken(ctx context.Context, token *model.RevokedAccessToken) error {
+	query := `
+		INSERT INTO auth.revoked_access_tokens (id, token_id, user_id, expires_at, revoked_at, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID,
+		token.TokenID,
+		token.UserID,
+		token.ExpiresAt,
+		token.RevokedAt,
+		token.Reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token jti has been revoked
+func (r *tokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM auth.revoked_access_tokens WHERE token_id = $1)"
+
+	var revoked bool
+	err := r.db.QueryRowContext(ctx, query, tokenID).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check revoked access token: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// PurgeExpiredTokens deletes refresh, password reset, email verification and revoked
+// access tokens that expired before the given time
+func (r *tokenRepository) PurgeExpiredTokens(ctx context.Context, expiredBefore time.Time) (*model.TokenPurgeResult, error) {
+	result := &model.TokenPurgeResult{}
+	tables := []struct {
+		name  string
+		count *int64
+	}{
+		{"refresh_tokens", &result.RefreshTokens},
+		{"password_reset_tokens", &result.PasswordResetTokens},
+		{"email_verification_tokens", &result.EmailVerificationTokens},
+		{"revoked_access_tokens", &result.RevokedAccessTokens},
+	}
+
+	for _, table := range tables {
+		query := "DELETE FROM auth." + table.name + " WHERE expires_at < $1"
+
+		res, err := r.db.ExecContext(ctx, query, expiredBefore)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge %s: %w", table.name, err)
+		}
+
+		deleted, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		*table.count = deleted
+	}
+
+	return result, nil
+}
+
+// Close closes the database connection
+func (r *tokenRepository) Close() error {
+	return r.db.Close()
+}
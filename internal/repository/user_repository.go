@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/auth-service/internal/config"
+	"github.com/yourusername/auth-service/internal/model"
+)
+
+// ErrEmailAlreadyExists is returned when a user with the same email already exists
+var ErrEmailAlreadyExists = errors.New("email already exists")
+
+// UserRepository defines the interface for user data access
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *model.User) error
+	GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error
+	RecordFailedLogin(ctx context.Context, id uuid.UUID, cfg config.LockoutConfig) (*time.Time, error)
+	UnlockUser(ctx context.Context, id uuid.UUID) (bool, error)
+	UpdateUserRole(ctx context.Context, id uuid.UUID, role model.Role) (bool, error)
+	BootstrapAdmin(ctx context.Context, email string) (bool, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, update *model.UserUpdate) error
+	SoftDeleteUser(ctx context.Context, id uuid.UUID) error
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
+	Close() error
+}
+
+// userRepository implements UserRepository with PostgreSQL
+type userRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db *sql.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// userColumns lists the columns selected for a user
+const userColumns = `
+	id, email, password_hash, first_name, last_name, is_active,
+	is_verified, role, totp_secret, totp_enabled, last_login_at, failed_login_attempts, lockout_count,
+	locked_until, created_at, updated_at, deleted_at
+`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a single user row
+func scanUser(row rowScanner) (*model.User, error) {
+	var user model.User
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.FirstName,
+		&user.LastName,
+		&user.IsActive,
+		&user.IsVerified,
+		&user.Role,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
+		&user.LastLoginAt,
+		&user.FailedLoginAttempts,
+		&user.LockoutCount,
+		&user.LockedUntil,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateUser inserts a new user and fills in database-generated fields
+func (r *userRepository) CreateUser(ctx context.Context, user *model.User) error {
+	query := `
+		INSERT INTO auth.users (
+			id, email, password_hash, first_name, last_name, is_active, is_verified, role
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		user.ID,
+		user.Email,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.IsActive,
+		user.IsVerified,
+		user.Role,
+	).Scan(&user.CreatedAt, &user.UpdatedAt)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserByID retrieves a user by ID
+func (r *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM auth.users WHERE id = $1"
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // User not found
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail retrieves a user by normalized email
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM auth.users WHERE email = $1"
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // User not found
+		}
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return user, nil
+}
+
+// UpdateLastLogin records the time of a user's last successful login and clears failed attempts
+func (r *userRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	query := `
+		UPDATE auth.users
+		SET last_login_at = $1, failed_login_attempts = 0, lockout_count = 0, locked_until = NULL
+		WHERE id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, loginAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailedLogin counts a failed login and locks the account once the threshold is
+// reached. Each consecutive lockout doubles the lock duration up to the configured maximum.
+// It returns the lock expiry if this attempt locked the account.
+func (r *userRepository) RecordFailedLogin(ctx context.Context, id uuid.UUID, cfg config.LockoutConfig) (*time.Time, error) {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var attempts, lockouts int
+	selectQuery := "SELECT failed_login_attempts, lockout_count FROM auth.users WHERE id = $1 FOR UPDATE"
+	if err := tx.QueryRowContext(ctx, selectQuery, id).Scan(&attempts, &lockouts); err != nil {
+		return nil, fmt.Errorf("failed to get failed login attempts: %w", err)
+	}
+
+	attempts++
+
+	var lockedUntil *time.Time
+	if cfg.Enabled && cfg.MaxFailedAttempts > 0 && attempts >= cfg.MaxFailedAttempts {
+		duration := cfg.BaseDuration
+		for i := 0; i < lockouts && duration < cfg.MaxDuration; i++ {
+			duration *= 2
+		}
+		if cfg.MaxDuration > 0 && duration > cfg.MaxDuration {
+			duration = cfg.MaxDuration
+		}
+
+		until := time.Now().Add(duration)
+		lockedUntil = &until
+		attempts = 0
+		lockouts++
+	}
+
+	updateQuery := `
+		UPDATE auth.users
+		SET failed_login_attempts = $1, lockout_count = $2, locked_until = COALESCE($3, locked_until)
+		WHERE id = $4
+	`
+	if _, err := tx.ExecContext(ctx, updateQuery, attempts, lockouts, lockedUntil, id); err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return lockedUntil, nil
+}
+
+// UnlockUser clears the lockout state of a user, reporting whether the user exists
+func (r *userRepository) UnlockUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE auth.users
+		SET failed_login_attempts = 0, lockout_count = 0, locked_until = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateUserRole changes the role of a user, reporting whether the user exists
+func (r *userRepository) UpdateUserRole(ctx context.Context, id uuid.UUID, role model.Role) (bool, error) {
+	query := `
+		UPDATE auth.users
+		SET role = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, role, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// BootstrapAdmin promotes the user with the given email to admin unless an admin
+// already exists. It reports whether the user was promoted.
+func (r *userRepository) BootstrapAdmin(ctx context.Context, email string) (bool, error) {
+	query := `
+		UPDATE auth.users
+		SET role = 'admin', updated_at = NOW()
+		WHERE email = $1 AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM auth.users WHERE role = 'admin' AND deleted_at IS NULL
+			)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, email)
+	if err != nil {
+		return false, fmt.Errorf("failed to bootstrap admin: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateUser updates profile fields of a user
+func (r *userRepository) UpdateUser(ctx context.Context, id uuid.UUID, update *model.UserUpdate) error {
+	// Build dynamic query based on provided fields
+	query := "UPDATE auth.users SET "
+	args := []interface{}{}
+	argIndex := 1
+
+	if update.FirstName != nil {
+		query += fmt.Sprintf("first_name = $%d, ", argIndex)
+		args = append(args, *update.FirstName)
+		argIndex++
+	}
+
+	if update.LastName != nil {
+		query += fmt.Sprintf("last_name = $%d, ", argIndex)
+		args = append(args, *update.LastName)
+		argIndex++
+	}
+
+	if update.IsActive != nil {
+		query += fmt.Sprintf("is_active = $%d, ", argIndex)
+		args = append(args, *update.IsActive)
+		argIndex++
+	}
+
+	// Remove trailing comma and space
+	if len(args) == 0 {
+		return nil // Nothing to update
+	}
+
+	query = query[:len(query)-2] // Remove ", "
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", argIndex)
+	args = append(args, id)
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return nil
+}
+
+// SoftDeleteUser marks a user as deleted and revokes all of their refresh tokens
+func (r *userRepository) SoftDeleteUser(ctx context.Context, id uuid.UUID) error {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleteQuery := "UPDATE auth.users SET deleted_at = NOW(), is_active = FALSE WHERE id = $1 AND deleted_at IS NULL"
+	_, err = tx.ExecContext(ctx, deleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	revokeQuery := "UPDATE auth.refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+	_, err = tx.ExecContext(ctx, revokeQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes users soft-deleted before the given time,
+// together with their diary entries. It returns the number of purged users.
+func (r *userRepository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Use transaction to ensure atomicity
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	diaryQuery := `
+		DELETE FROM diary.food_entries
+		WHERE user_id IN (
+			SELECT id FROM auth.users WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		)
+	`
+	_, err = tx.ExecContext(ctx, diaryQuery, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge diary entries: %w", err)
+	}
+
+	userQuery := "DELETE FROM auth.users WHERE deleted_at IS NOT NULL AND deleted_at < $1"
+	result, err := tx.ExecContext(ctx, userQuery, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge users: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return purged, nil
+}
+
+// Close closes the database connection
+func (r *userRepository) Close() error {
+	return r.db.Close()
+}
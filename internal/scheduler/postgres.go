@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+)
+
+// advisoryLockNamespace separates scheduler lock keys from other advisory lock users
+const advisoryLockNamespace = "scheduler:"
+
+// PostgresCoordinator makes a job run on only one replica per interval. A replica
+// must hold the job's session advisory lock to run it, and a run is skipped when
+// auth.scheduled_jobs shows another replica started it less than half an interval ago.
+type PostgresCoordinator struct {
+	db *sql.DB
+}
+
+// NewPostgresCoordinator creates a coordinator backed by Postgres advisory locks
+func NewPostgresCoordinator(db *sql.DB) *PostgresCoordinator {
+	return &PostgresCoordinator{db: db}
+}
+
+// Acquire implements Coordinator
+func (p *PostgresCoordinator) Acquire(ctx context.Context, name string, interval time.Duration) (func(error), bool, error) {
+	// Session advisory locks belong to one connection, so pin it for the whole run
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	key := lockKey(name)
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// The run context may already be done; unlocking must still happen
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("Scheduler: failed to release lock for job %s: %v", name, err)
+		}
+		conn.Close()
+	}
+
+	// Claim the run unless another replica started it recently
+	claimQuery := `
+		INSERT INTO auth.scheduled_jobs (name, last_started_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (name) DO UPDATE SET last_started_at = NOW()
+		WHERE auth.scheduled_jobs.last_started_at IS NULL
+			OR auth.scheduled_jobs.last_started_at < NOW() - $2 * INTERVAL '1 second'
+	`
+	result, err := conn.ExecContext(ctx, claimQuery, name, (interval / 2).Seconds())
+	if err != nil {
+		unlock()
+		return nil, false, fmt.Errorf("failed to claim job run: %w", err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		unlock()
+		return nil, false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if claimed == 0 {
+		unlock()
+		return nil, false, nil
+	}
+
+	release := func(runErr error) {
+		var lastError *string
+		if runErr != nil {
+			msg := runErr.Error()
+			lastError = &msg
+		}
+
+		finishCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		finishQuery := "UPDATE auth.scheduled_jobs SET last_finished_at = NOW(), last_error = $1 WHERE name = $2"
+		if _, err := conn.ExecContext(finishCtx, finishQuery, lastError, name); err != nil {
+			log.Printf("Scheduler: failed to record result of job %s: %v", name, err)
+		}
+
+		unlock()
+	}
+
+	return release, true, nil
+}
+
+// lockKey maps a job name to a 64-bit advisory lock key
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(advisoryLockNamespace + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrSchedulerStarted is returned when registering a job after Start
+var ErrSchedulerStarted = errors.New("scheduler already started")
+
+// Job is a periodic task run by the scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Timeout bounds a single run; zero means the run may take up to Interval
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Coordinator decides whether this process should run a job now. It lets several
+// replicas share one schedule so that each run happens on only one of them.
+type Coordinator interface {
+	// Acquire reports whether the job should run now. When ok is true, release must be
+	// called with the result of the run.
+	Acquire(ctx context.Context, name string, interval time.Duration) (release func(runErr error), ok bool, err error)
+}
+
+// Scheduler runs registered jobs at their intervals, adding a random jitter to every
+// wait so replicas started together do not fire at the same moment
+type Scheduler struct {
+	coordinator Coordinator
+	jitter      time.Duration
+
+	mu      sync.Mutex
+	jobs    []Job
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a scheduler. A nil coordinator runs every job on every tick.
+func New(coordinator Coordinator, jitter time.Duration) *Scheduler {
+	return &Scheduler{
+		coordinator: coordinator,
+		jitter:      jitter,
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job must have a name and a run function")
+	}
+	if job.Interval <= 0 {
+		return fmt.Errorf("job %s must have a positive interval", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrSchedulerStarted
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == job.Name {
+			return fmt.Errorf("job %s is already registered", job.Name)
+		}
+	}
+
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Start runs all registered jobs in the background until Stop is called or ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// loop runs one job until ctx is done
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	// Spread the first runs of all replicas across the jitter window
+	wait := s.randomJitter()
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runOnce(ctx, job)
+		wait = job.Interval + s.randomJitter()
+	}
+}
+
+// runOnce runs a job if the coordinator allows it, logging failures
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = job.Interval
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	release := func(error) {}
+	if s.coordinator != nil {
+		var ok bool
+		var err error
+		release, ok, err = s.coordinator.Acquire(runCtx, job.Name, job.Interval)
+		if err != nil {
+			log.Printf("Scheduler: failed to acquire job %s: %v", job.Name, err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+
+	start := time.Now()
+	err := job.Run(runCtx)
+	release(err)
+
+	if err != nil {
+		log.Printf("Scheduler: job %s failed after %s: %v", job.Name, time.Since(start).Round(time.Millisecond), err)
+	}
+}
+
+// randomJitter returns a random delay up to the configured jitter
+func (s *Scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.jitter)))
+}
@@ -0,0 +1,20 @@
+-- Revert refresh token rotation columns
+SET search_path TO auth;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_user_device;
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+
+-- Only one token per device is allowed again
+DELETE FROM refresh_tokens WHERE revoked_at IS NOT NULL;
+DELETE FROM refresh_tokens a
+    USING refresh_tokens b
+    WHERE a.user_id = b.user_id
+      AND a.device_info = b.device_info
+      AND a.created_at < b.created_at;
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS replaced_by;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS family_id;
+ALTER TABLE refresh_tokens ADD CONSTRAINT refresh_tokens_user_id_device_info_key UNIQUE (user_id, device_info);
+
+-- Reset search path
+RESET search_path;
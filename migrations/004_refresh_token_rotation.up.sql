@@ -0,0 +1,20 @@
+-- Refresh token rotation: keep rotated tokens so reuse can be detected
+SET search_path TO auth;
+
+-- A device may now have a chain of rotated tokens
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS refresh_tokens_user_id_device_info_key;
+
+-- Tokens issued from the same login share a family
+ALTER TABLE refresh_tokens ADD COLUMN family_id UUID;
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+-- Token that replaced this one during rotation
+ALTER TABLE refresh_tokens ADD COLUMN replaced_by UUID;
+
+-- Indexes for rotation lookups
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_user_device ON refresh_tokens(user_id, device_info) WHERE revoked_at IS NULL;
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,2 @@
+-- Drop email verification tokens
+DROP TABLE IF EXISTS auth.email_verification_tokens;
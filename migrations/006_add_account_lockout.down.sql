@@ -0,0 +1,9 @@
+-- Drop account lockout columns
+SET search_path TO auth;
+
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS lockout_count;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_login_attempts;
+
+-- Reset search path
+RESET search_path;
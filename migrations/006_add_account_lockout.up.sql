@@ -0,0 +1,9 @@
+-- Account lockout after repeated failed logins
+SET search_path TO auth;
+
+ALTER TABLE users ADD COLUMN failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN lockout_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+-- Reset search path
+RESET search_path;
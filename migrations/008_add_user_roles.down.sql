@@ -0,0 +1,8 @@
+-- Drop user roles
+SET search_path TO auth;
+
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,10 @@
+-- User roles
+SET search_path TO auth;
+
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'dietitian', 'admin'));
+
+CREATE INDEX idx_users_role ON users(role);
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,9 @@
+-- Drop TOTP two-factor authentication
+SET search_path TO auth;
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,20 @@
+-- TOTP two-factor authentication
+SET search_path TO auth;
+
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Single-use recovery codes
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(255) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes for recovery codes
+CREATE INDEX idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id, code_hash);
+
+-- Reset search path
+RESET search_path;
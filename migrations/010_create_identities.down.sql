@@ -0,0 +1,3 @@
+-- Drop external identities
+DROP TABLE IF EXISTS auth.oidc_states;
+DROP TABLE IF EXISTS auth.identities;
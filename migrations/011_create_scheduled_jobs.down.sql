@@ -0,0 +1,2 @@
+-- Drop background job runs
+DROP TABLE IF EXISTS auth.scheduled_jobs;
@@ -0,0 +1,12 @@
+-- Background job runs shared by all replicas
+SET search_path TO auth;
+
+CREATE TABLE scheduled_jobs (
+    name VARCHAR(100) PRIMARY KEY,
+    last_started_at TIMESTAMP WITH TIME ZONE,
+    last_finished_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT
+);
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,4 @@
+-- Drop full-text and fuzzy food search
+DROP INDEX IF EXISTS nutrition.idx_foods_description_trgm;
+DROP INDEX IF EXISTS nutrition.idx_foods_search_vector;
+ALTER TABLE nutrition.foods DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,17 @@
+-- Full-text and fuzzy food search
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+SET search_path TO nutrition;
+
+-- Stemmed description words, kept in sync by Postgres
+ALTER TABLE foods
+    ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', description)) STORED;
+
+CREATE INDEX idx_foods_search_vector ON foods USING GIN (search_vector);
+
+-- Trigram index for typo-tolerant matching
+CREATE INDEX idx_foods_description_trgm ON foods USING GIN (description public.gin_trgm_ops);
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,4 @@
+-- Drop food snapshots from diary entries
+ALTER TABLE diary.food_entries
+    DROP COLUMN IF EXISTS nutrients_per_100g,
+    DROP COLUMN IF EXISTS food_description;
@@ -0,0 +1,27 @@
+-- Immutable food snapshots on diary entries
+SET search_path TO diary;
+
+-- Food description and full per-100 g nutrient vector captured at log time,
+-- so later USDA imports never change past diaries
+ALTER TABLE food_entries
+    ADD COLUMN food_description TEXT,
+    ADD COLUMN nutrients_per_100g JSONB;
+
+-- Backfill existing entries from the current food data
+UPDATE food_entries e
+SET food_description = f.description,
+    nutrients_per_100g = (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'nutrient_id', n.nutrient_id,
+            'name', n.nutrient_name,
+            'unit', n.unit_name,
+            'amount', n.amount
+        ) ORDER BY n.nutrient_id), '[]'::jsonb)
+        FROM nutrition.food_nutrients n
+        WHERE n.fdc_id = f.fdc_id
+    )
+FROM nutrition.foods f
+WHERE f.fdc_id = e.fdc_id;
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,2 @@
+-- Drop nutrition goals
+DROP TABLE IF EXISTS diary.nutrition_goals;
@@ -0,0 +1,31 @@
+-- Per-user nutrition goals
+SET search_path TO diary;
+
+-- A goal applies from effective_from until the next goal of the user, so past
+-- days keep being judged against the goal active at that time
+CREATE TABLE nutrition_goals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL,
+    calories DECIMAL(10,2) CHECK (calories > 0),
+    protein_grams DECIMAL(10,2) CHECK (protein_grams > 0),
+    protein_percent DECIMAL(5,2) CHECK (protein_percent > 0 AND protein_percent <= 100),
+    fat_grams DECIMAL(10,2) CHECK (fat_grams > 0),
+    fat_percent DECIMAL(5,2) CHECK (fat_percent > 0 AND fat_percent <= 100),
+    carbs_grams DECIMAL(10,2) CHECK (carbs_grams > 0),
+    carbs_percent DECIMAL(5,2) CHECK (carbs_percent > 0 AND carbs_percent <= 100),
+    -- Micronutrient targets: [{"nutrient_id": 1092, "amount": 3400}]
+    micronutrients JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (user_id, effective_from),
+
+    -- A macro target is either in grams or in percent of energy
+    CONSTRAINT chk_protein_target CHECK (protein_grams IS NULL OR protein_percent IS NULL),
+    CONSTRAINT chk_fat_target CHECK (fat_grams IS NULL OR fat_percent IS NULL),
+    CONSTRAINT chk_carbs_target CHECK (carbs_grams IS NULL OR carbs_percent IS NULL)
+);
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,7 @@
+-- Drop last accepted TOTP time step
+SET search_path TO auth;
+
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,7 @@
+-- Last accepted TOTP time step, so a code cannot be used twice
+SET search_path TO auth;
+
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT;
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,8 @@
+-- Drop browser and session binding of authorization requests
+SET search_path TO auth;
+
+ALTER TABLE oidc_states DROP COLUMN IF EXISTS session_id;
+ALTER TABLE oidc_states DROP COLUMN IF EXISTS binding_hash;
+
+-- Reset search path
+RESET search_path;
@@ -0,0 +1,11 @@
+-- Bind pending authorization requests to the browser and session that started them
+SET search_path TO auth;
+
+-- Requests started before this migration cannot be completed
+DELETE FROM oidc_states;
+
+ALTER TABLE oidc_states ADD COLUMN binding_hash VARCHAR(255) NOT NULL;
+ALTER TABLE oidc_states ADD COLUMN session_id UUID;
+
+-- Reset search path
+RESET search_path;
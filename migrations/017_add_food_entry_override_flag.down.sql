@@ -0,0 +1,7 @@
+-- Drop nutrient override flag
+SET search_path TO diary;
+
+ALTER TABLE food_entries DROP COLUMN IF EXISTS nutrients_overridden;
+
+-- Reset search path
+RESET search_path;
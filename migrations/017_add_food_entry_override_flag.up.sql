@@ -0,0 +1,14 @@
+-- Remember entries whose USDA nutrients were replaced by the user
+SET search_path TO diary;
+
+ALTER TABLE food_entries ADD COLUMN nutrients_overridden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Nutrients of existing USDA entries were entered by the client, not calculated
+UPDATE food_entries
+SET nutrients_overridden = TRUE
+WHERE fdc_id IS NOT NULL
+    AND (calculated_calories IS NOT NULL OR calculated_protein IS NOT NULL
+        OR calculated_fat IS NOT NULL OR calculated_carbs IS NOT NULL);
+
+-- Reset search path
+RESET search_path;
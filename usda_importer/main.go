@@ -113,13 +113,17 @@ func createTables(db *sql.DB) error {
 		`DROP TABLE IF EXISTS input_foods CASCADE`,
 		`DROP TABLE IF EXISTS foods CASCADE`,
 
+		// Триграммы для нечеткого поиска продуктов
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public`,
+
 		// Основная таблица продуктов
 		`CREATE TABLE foods (
             fdc_id INTEGER PRIMARY KEY,
             description TEXT NOT NULL,
             data_type TEXT,
             food_class TEXT,
-            publication_date TEXT
+            publication_date TEXT,
+            search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', description)) STORED
         )`,
 
 		// Связующие таблицы
@@ -176,6 +180,8 @@ func createTables(db *sql.DB) error {
 		`CREATE INDEX idx_food_nutrients_fdc ON food_nutrients(fdc_id)`,
 		`CREATE INDEX idx_food_nutrients_nutrient ON food_nutrients(nutrient_id)`,
 		`CREATE INDEX idx_foods_description ON foods(description)`,
+		`CREATE INDEX idx_foods_search_vector ON foods USING GIN (search_vector)`,
+		`CREATE INDEX idx_foods_description_trgm ON foods USING GIN (description public.gin_trgm_ops)`,
 		`CREATE INDEX idx_food_portions_fdc ON food_portions(fdc_id)`,
 		`CREATE INDEX idx_food_attributes_fdc ON food_attributes(fdc_id)`,
 	}